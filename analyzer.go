@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+
+	"github.com/sharafdin/crackulator/common"
+	"github.com/sharafdin/crackulator/hash"
+	"github.com/sharafdin/crackulator/password"
+	"github.com/sharafdin/crackulator/profile"
+	"github.com/sharafdin/crackulator/report"
+)
+
+// analyzer holds the settings shared by every password analyzed in a run
+type analyzer struct {
+	hashName         string
+	system           string
+	params           hash.Params
+	theoreticalSpeed float64
+	benchmarkedSpeed float64 // zero when no benchmark was run
+	benchmarked      bool
+	benchmarkStats   *report.BenchmarkStats
+	sampleHash       bool // only set with --show-password, so redacted reports never hold the unsalted hash
+
+	// commonSources names the lists searched by commonChecks, which is empty when no check was requested
+	commonSources []string
+	commonChecks  []func(string) common.Match
+
+	// breachSource is the range API queried by breachCount, which is nil when no check was requested
+	breachSource string
+	breachCount  func(string) (int64, error)
+
+	// policy is checked against every password when set, with context as the account's words
+	policy  *password.Policy
+	context []string
+
+	// scenarios are the kinds of attacker the password is also measured against
+	scenarios []password.Scenario
+
+	// percentiles are the probabilities to report crack times for, and
+	// budget the time to report the chance of a crack within, if any
+	percentiles []float64
+	budgetSpec  string
+	budget      float64
+	devices     int
+
+	// rentals are the cloud instances to price the attack on
+	rentals []rental
+}
+
+// rental is a cloud instance with the speed of each of its GPUs for the selected hash
+type rental struct {
+	instance    *profile.Instance
+	profile     string
+	deviceSpeed float64
+}
+
+// The offline scenarios assume these hashes unless the selected hash is of the same kind
+const (
+	scenarioFastHash = "MD5"
+	scenarioSlowHash = "bcrypt"
+)
+
+// newAnalyzer resolves hash speeds for the selected algorithm and attacker
+// profile, benchmarking once if requested and no cached result exists. Profile speeds are
+// measured with the default cost parameters, so they are scaled to the chosen ones.
+func newAnalyzer(opts *options) *analyzer {
+	attacker, _ := opts.profiles.Find(opts.system)
+	speed, _ := attacker.Speed(opts.hashName)
+
+	a := &analyzer{
+		hashName:         opts.hashName,
+		system:           opts.system,
+		params:           opts.params,
+		theoreticalSpeed: speed / opts.params.CostFactor(opts.hashName),
+		sampleHash:       opts.showPassword,
+		policy:           opts.policy,
+		context:          opts.context,
+		percentiles:      opts.percentiles,
+		budgetSpec:       opts.budgetSpec,
+		budget:           opts.budget,
+		devices:          max(attacker.Devices, 1),
+	}
+
+	fastHash, slowHash := opts.hashName, scenarioSlowHash
+	if hash.IsSlow(opts.hashName) {
+		fastHash, slowHash = scenarioFastHash, opts.hashName
+	}
+	// Profile speeds assume the default cost parameters, so both are scaled to the chosen ones
+	fastSpeed, _ := attacker.Speed(fastHash)
+	slowSpeed, _ := attacker.Speed(slowHash)
+	fastSpeed /= opts.params.CostFactor(fastHash)
+	slowSpeed /= opts.params.CostFactor(slowHash)
+
+	// Instances are priced with per-device speeds scaled to the cost
+	// parameters; those whose profile lacks the hash are left out with a warning
+	for _, instance := range opts.instances {
+		name := instance.Profile
+		if name == "" {
+			name = opts.system
+		}
+		p, _ := opts.profiles.Find(name)
+		speed, ok := p.Speeds[opts.hashName]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Warning: leaving %s out of the cost table, as profile %q has no %s speed\n", instance.Name, name, opts.hashName)
+			continue
+		}
+		a.rentals = append(a.rentals, rental{instance: instance, profile: name, deviceSpeed: speed / opts.params.CostFactor(opts.hashName)})
+	}
+
+	a.scenarios = password.Scenarios(opts.throttle, opts.lockout, slowHash, slowSpeed, fastHash, fastSpeed)
+
+	if opts.breachCheck {
+		a.breachSource = opts.breachAPI
+		a.breachCount = func(p string) (int64, error) { return common.CheckBreached(p, opts.breachAPI) }
+	}
+
+	if opts.benchmark {
+		benchmark, cached := cachedBenchmark(opts)
+		benchmarkResult := benchmark.Result
+		a.benchmarkedSpeed = benchmarkResult.HashesPerSecond
+		a.benchmarked = true
+		a.benchmarkStats = &report.BenchmarkStats{
+			Workers:    benchmarkResult.Workers,
+			PerCore:    benchmarkResult.PerCore,
+			Rounds:     benchmarkResult.Rounds,
+			Min:        benchmarkResult.Min,
+			Median:     benchmarkResult.Median,
+			StdDev:     benchmarkResult.StdDev,
+			MeasuredAt: benchmark.MeasuredAt,
+			Cached:     cached,
+		}
+	}
+
+	return a
+}
+
+// analyze computes the full report for a single password
+func (a *analyzer) analyze(passwordInput string) *report.Report {
+	// 1. Analyze the password
+	analysis := password.Analyze(passwordInput)
+	hasLower, hasUpper, hasDigit, hasSpecial := analysis.CharacterClasses()
+	strength := password.GetStrength(passwordInput, analysis.Length, hasLower, hasUpper, hasDigit, hasSpecial)
+
+	// 2. Calculate possible combinations over the scripts that appear
+	combinations := password.CalculateCombinations(analysis.Length, analysis.CharsetSize)
+
+	// 3. Estimate the guesses needed by an attacker who exploits patterns,
+	// which never exceeds the naive brute-force keyspace
+	estimate := password.EstimateGuesses(passwordInput)
+	guesses := estimate.Guesses
+	if guesses.Cmp(combinations) > 0 {
+		guesses = combinations
+	}
+
+	// 4. A password in a common list, or derived from one by a rule, falls
+	// as soon as the attacker reaches it, so its guess number caps the guesses
+	var match common.Match
+	if len(a.commonChecks) > 0 {
+		matches := make([]common.Match, 0, len(a.commonChecks))
+		for _, check := range a.commonChecks {
+			matches = append(matches, check(passwordInput))
+		}
+		match = common.Best(matches...)
+	}
+	guessesLog10 := estimate.GuessesLog10
+	if match.Found && match.Guesses > 0 && big.NewInt(match.Guesses).Cmp(guesses) < 0 {
+		guesses = big.NewInt(match.Guesses)
+		guessesLog10 = math.Log10(float64(match.Guesses))
+	}
+
+	// 5. Calculate cracking time and its interpretation. A list rank or a
+	// pattern is reached at a known guess, while a password with a
+	// brute-forced part may lie anywhere within its guesses.
+	theoreticalSeconds := password.CrackSeconds(guesses, a.theoreticalSpeed)
+	interpretation := password.InterpretCrackTime(theoreticalSeconds)
+	ranked := match.Found && match.Guesses > 0 && big.NewInt(match.Guesses).Cmp(guesses) == 0
+	distribution := password.NewGuessDistribution(guesses, !ranked && (estimate.HasBruteforce() || guesses.Cmp(combinations) == 0))
+
+	result := &report.Report{
+		Password: passwordInput,
+		Length:   analysis.Length,
+		Runes:    analysis.Runes,
+		Bytes:    analysis.Bytes,
+		Composition: report.Composition{
+			Lower:    analysis.HasLower,
+			Upper:    analysis.HasUpper,
+			Caseless: analysis.HasCaseless,
+			Digit:    analysis.HasDigit,
+			Special:  analysis.HasSpecial,
+			Emoji:    analysis.HasEmoji,
+		},
+		Scripts:       analysis.Scripts,
+		Categories:    analysis.Categories,
+		Charsets:      report.NewCharsets(analysis.Charsets),
+		CharsetSize:   analysis.CharsetSize,
+		Combinations:  combinations,
+		Strength:      strength,
+		Guesses:       guesses,
+		GuessesLog10:  guessesLog10,
+		Patterns:      report.NewPatterns(estimate.Sequence),
+		KeyboardWalks: report.NewKeyboardWalks(estimate.KeyboardWalks),
+		Hash: report.HashInfo{
+			Algorithm:        a.hashName,
+			System:           a.system,
+			Parameters:       a.params.Describe(a.hashName),
+			TheoreticalSpeed: a.theoreticalSpeed,
+		},
+		Theoretical:    report.NewCrackTime(theoreticalSeconds),
+		GuessOrder:     "rank",
+		Expected:       report.NewCrackTime(password.SecondsFor(distribution.Expected(), a.theoreticalSpeed)),
+		BruteForce:     report.NewCrackTime(password.CrackSeconds(combinations, a.theoreticalSpeed)),
+		Scenarios:      report.NewScenarios(a.scenarios, guesses),
+		Interpretation: interpretation,
+	}
+
+	if !distribution.Exact {
+		result.GuessOrder = "uniform"
+		for _, p := range a.percentiles {
+			result.Percentiles = append(result.Percentiles, report.Percentile{
+				Percent:   p,
+				CrackTime: report.NewCrackTime(password.SecondsFor(distribution.Percentile(p), a.theoreticalSpeed)),
+			})
+		}
+	}
+	if a.budget > 0 {
+		tried := new(big.Float).Mul(big.NewFloat(a.budget), big.NewFloat(a.theoreticalSpeed))
+		result.Budget = &report.Budget{
+			Duration:    a.budgetSpec,
+			Seconds:     a.budget,
+			Devices:     a.devices,
+			Probability: distribution.Probability(tried),
+		}
+	}
+
+	for _, r := range a.rentals {
+		result.Costs = append(result.Costs, report.CrackCost{
+			Instance:        r.instance.Name,
+			Provider:        r.instance.Provider,
+			GPUs:            r.instance.GPUs,
+			PricePerGPUHour: r.instance.PricePerGPUHour,
+			Profile:         r.profile,
+			Exhaust:         report.NewPrice(r.instance.Cost(new(big.Float).SetInt(guesses), r.deviceSpeed)),
+			Median:          report.NewPrice(r.instance.Cost(distribution.Percentile(50), r.deviceSpeed)),
+		})
+	}
+
+	// 6. Report the common password check if requested
+	if len(a.commonChecks) > 0 {
+		result.CommonCheck = &report.CommonCheck{
+			Lists:   a.commonSources,
+			Found:   match.Found,
+			List:    match.List,
+			Rank:    match.Rank,
+			Count:   match.Count,
+			Guesses: match.Guesses,
+			Base:    match.Base,
+			Rule:    match.Rule,
+		}
+	}
+
+	// 7. Look the password up in the breach corpus if requested
+	if a.breachCount != nil {
+		result.BreachCheck = &report.BreachCheck{Source: a.breachSource}
+		count, err := a.breachCount(passwordInput)
+		if err != nil {
+			result.BreachCheck.Error = err.Error()
+		} else {
+			result.BreachCheck.Found = count > 0
+			result.BreachCheck.Count = count
+		}
+	}
+
+	// 8. Check the policy, which treats a common or breached password as blocklisted
+	if a.policy != nil {
+		facts := password.PolicyFacts{GuessesLog10: guessesLog10, Context: a.context}
+		switch {
+		case match.Found:
+			facts.Blocklisted = match.List
+		case result.BreachCheck != nil && result.BreachCheck.Found:
+			facts.Blocklisted = result.BreachCheck.Source
+		}
+		result.Policy = report.NewPolicyCheck(a.policy.Check(passwordInput, facts))
+	}
+
+	// 9. Turn the findings into advice, with the crack time of a longer password for comparison
+	result.Suggestions = a.suggestions(passwordInput, estimate, match, result)
+	extra := password.WhatIfLength(guesses, analysis.CharsetSize, whatIfExtraLength, a.theoreticalSpeed)
+	longer := password.GrowGuesses(guesses, analysis.CharsetSize, extra)
+	result.WhatIf = &report.WhatIf{
+		ExtraCharacters: extra,
+		Guesses:         longer,
+		CrackTime:       report.NewCrackTime(password.CrackSeconds(longer, a.theoreticalSpeed)),
+	}
+
+	// 10. Only calculate benchmarked time if benchmark was run
+	if a.benchmarked {
+		benchmarked := report.NewCrackTime(password.CrackSeconds(guesses, a.benchmarkedSpeed))
+		result.Hash.BenchmarkedSpeed = a.benchmarkedSpeed
+		result.Hash.Benchmark = a.benchmarkStats
+		result.Benchmarked = &benchmarked
+	}
+
+	// 11. Generate hash sample
+	if a.sampleHash {
+		hashFunction := a.params.Function(a.hashName)
+		result.Hash.Sample = hash.Format(hashFunction([]byte(passwordInput)))
+	}
+
+	return result
+}
+
+// whatIfExtraLength is the fewest characters the report adds to the
+// password to show how quickly the crack time grows; more are added when the
+// longer password would still be cracked too soon to show the difference
+const whatIfExtraLength = 2
+
+// suggestions lists what makes the password weak and how to fix it, most
+// serious first: list and breach hits, guessable patterns, failed policy
+// rules, then how much longer it needs to be
+func (a *analyzer) suggestions(passwordInput string, estimate password.GuessEstimate, match common.Match, result *report.Report) []string {
+	var suggestions []string
+	switch {
+	case match.Found && match.Rule != "":
+		suggestions = append(suggestions, fmt.Sprintf("is %q from %s changed by the rule %q, which a rule attack tries at guess %d; choose a different password", match.Base, match.List, match.Rule, match.Guesses))
+	case match.Found:
+		suggestions = append(suggestions, fmt.Sprintf("appears in %s; choose a different password", match.List))
+	}
+	if result.BreachCheck != nil && result.BreachCheck.Found {
+		suggestions = append(suggestions, fmt.Sprintf("has appeared %d times in data breaches; never use it again", result.BreachCheck.Count))
+	}
+
+	// Patterns only matter while they keep the password within reach
+	extra := password.ExtraLengthFor(result.Guesses, result.CharsetSize, a.theoreticalSpeed, password.StrongCrackSeconds)
+	if extra > 0 {
+		suggestions = append(suggestions, password.PatternFeedback(passwordInput, estimate)...)
+	}
+
+	if result.Policy != nil {
+		for _, rule := range result.Policy.Rules {
+			if rule.Passed {
+				continue
+			}
+			message := fmt.Sprintf("fails the %s rule: %s", result.Policy.Policy, rule.Description)
+			if rule.Detail != "" {
+				message += " (" + rule.Detail + ")"
+			}
+			suggestions = append(suggestions, message)
+		}
+	}
+
+	if extra > 0 {
+		suggestions = append(suggestions, fmt.Sprintf("add %d more random characters to reach 10 years against %s with %s", extra, a.system, a.hashName))
+	}
+	return suggestions
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sharafdin/crackulator/common"
+	"github.com/sharafdin/crackulator/hash"
+	"github.com/sharafdin/crackulator/password"
+	"github.com/sharafdin/crackulator/profile"
+	"github.com/sharafdin/crackulator/report"
+)
+
+// testAnalyzer returns an analyzer that reports every section derived from
+// the guess count, with a common list that holds the password at rank
+func testAnalyzer(rank int64) *analyzer {
+	throttle, _ := password.ParseThrottle("100/1h")
+	policy := password.Policies["nist"]
+	policy.MinGuessesLog10 = 10
+
+	a := &analyzer{
+		hashName:         "MD5",
+		system:           "High-end GPU",
+		params:           hash.DefaultParams(),
+		theoreticalSpeed: 5e8,
+		benchmarkedSpeed: 1e7,
+		benchmarked:      true,
+		benchmarkStats:   &report.BenchmarkStats{Workers: 4},
+		policy:           &policy,
+		scenarios:        password.Scenarios(throttle, 100, "bcrypt", 1e5, "MD5", 1e10),
+		percentiles:      []float64{10, 50, 90},
+		budgetSpec:       "30d",
+		budget:           30 * 86400,
+		devices:          8,
+		rentals: []rental{{
+			instance:    &profile.Instance{Name: "p5.48xlarge", GPUs: 8, PricePerGPUHour: 12.29},
+			profile:     "High-end GPU",
+			deviceSpeed: 1e9,
+		}},
+	}
+	a.commonSources = []string{"test.txt"}
+	a.commonChecks = []func(string) common.Match{func(string) common.Match {
+		return common.Match{Found: true, List: "test.txt", Rank: rank, Count: rank * 3, Guesses: rank}
+	}}
+	return a
+}
+
+// TestRedactWithholdsRank checks that a redacted report of a listed password
+// is the same whatever its rank, so no field can be worked back to the rank
+func TestRedactWithholdsRank(t *testing.T) {
+	const pw = "sunshine99"
+	var firstPlain, firstJSON, firstText []byte
+	for _, rank := range []int64{1, 4, 5184, 1e6, 1e12} {
+		result := testAnalyzer(rank).analyze(pw)
+		redacted := report.Redact(result)
+
+		var plain, json, text bytes.Buffer
+		if err := report.WriteJSON(&plain, result); err != nil {
+			t.Fatal(err)
+		}
+		if err := report.WriteJSON(&json, redacted); err != nil {
+			t.Fatal(err)
+		}
+		report.WriteText(&text, redacted)
+
+		if firstPlain == nil {
+			firstPlain, firstJSON, firstText = plain.Bytes(), json.Bytes(), text.Bytes()
+			continue
+		}
+		if bytes.Equal(plain.Bytes(), firstPlain) {
+			t.Fatalf("unredacted report at rank %d does not depend on the rank", rank)
+		}
+		if !bytes.Equal(json.Bytes(), firstJSON) {
+			t.Errorf("redacted JSON at rank %d differs from the one at rank 1:\n%s\nwant:\n%s", rank, json.Bytes(), firstJSON)
+		}
+		if !bytes.Equal(text.Bytes(), firstText) {
+			t.Errorf("redacted text at rank %d differs from the one at rank 1:\n%s\nwant:\n%s", rank, text.Bytes(), firstText)
+		}
+	}
+}
+
+// TestRedactKeepsUnlistedEstimates checks that a password not found in any
+// list keeps its crack times
+func TestRedactKeepsUnlistedEstimates(t *testing.T) {
+	a := testAnalyzer(0)
+	a.commonChecks = []func(string) common.Match{func(string) common.Match { return common.Match{} }}
+
+	r := report.Redact(a.analyze("sunshine99"))
+	if r.Guesses == nil || r.Theoretical.Withheld || r.WhatIf == nil || len(r.Costs) != 1 || r.Budget == nil {
+		t.Errorf("estimates of an unlisted password were withheld: %+v", r)
+	}
+	if r.Guesses.Cmp(big.NewInt(0)) <= 0 {
+		t.Errorf("Guesses = %v, want a positive estimate", r.Guesses)
+	}
+}
+
+// TestSampleHashNeedsShowPassword checks that the password is only hashed
+// for the report when it is shown
+func TestSampleHashNeedsShowPassword(t *testing.T) {
+	for _, show := range []bool{false, true} {
+		opts := &options{hashName: "MD5", system: "High-end GPU", params: hash.DefaultParams(), profiles: profile.NewSet(), showPassword: show}
+		r := newAnalyzer(opts).analyze("sunshine99")
+		if got := r.Hash.Sample != ""; got != show {
+			t.Errorf("with showPassword %v, sample hash %q", show, r.Hash.Sample)
+		}
+	}
+}
+
+// captureStderr returns what fn writes to standard error
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// TestNewAnalyzerSkipsInstances checks that instances whose profile has no
+// speed for the hash are left out of the cost table with a warning
+func TestNewAnalyzerSkipsInstances(t *testing.T) {
+	profiles := profile.NewSet()
+	profiles.Add(&profile.Profile{Name: "SHA-1 rig", Devices: 1, Speeds: map[string]float64{"SHA-1": 1e9}})
+	opts := &options{
+		hashName: "MD5",
+		system:   "High-end GPU",
+		params:   hash.DefaultParams(),
+		profiles: profiles,
+		instances: []*profile.Instance{
+			{Name: "p5.48xlarge", GPUs: 8, PricePerGPUHour: 12.29, Profile: "High-end GPU"},
+			{Name: "sha1-box", GPUs: 4, PricePerGPUHour: 1, Profile: "SHA-1 rig"},
+			{Name: "default-box", GPUs: 1, PricePerGPUHour: 2},
+		},
+	}
+
+	var a *analyzer
+	warnings := captureStderr(t, func() { a = newAnalyzer(opts) })
+	if want := "Warning: leaving sha1-box out of the cost table, as profile \"SHA-1 rig\" has no MD5 speed\n"; warnings != want {
+		t.Errorf("warnings %q, want %q", warnings, want)
+	}
+
+	var names []string
+	for _, r := range a.rentals {
+		names = append(names, r.instance.Name+"/"+r.profile)
+	}
+	// An instance without a profile takes the selected system's speed
+	if got, want := strings.Join(names, " "), "p5.48xlarge/High-end GPU default-box/High-end GPU"; got != want {
+		t.Errorf("rentals %q, want %q", got, want)
+	}
+
+	costs := a.analyze("Tr0ub4dor&3xq").Costs
+	if len(costs) != 2 {
+		t.Fatalf("%d costs, want 2", len(costs))
+	}
+}
+
+// TestCostsExhaustAndMedian checks that a brute-forced password is cracked
+// halfway through the search on average, and a password found by rank only
+// once the attacker reaches it
+func TestCostsExhaustAndMedian(t *testing.T) {
+	tests := []struct {
+		password string
+		ratio    float64
+	}{
+		{"zq8vx2mk", 0.5},
+		{"sunshine", 1},
+	}
+	for _, tt := range tests {
+		a := testAnalyzer(0)
+		a.commonChecks = nil
+		c := a.analyze(tt.password).Costs[0]
+		if c.Exhaust.Dollars <= 0 {
+			t.Fatalf("%q: exhausting the search costs $%v", tt.password, c.Exhaust.Dollars)
+		}
+		if got := c.Median.Dollars / c.Exhaust.Dollars; math.Abs(got-tt.ratio) > 1e-9 {
+			t.Errorf("%q: median costs %v of the exhaustive search, want %v", tt.password, got, tt.ratio)
+		}
+		if got := c.Median.GPUHours / c.Exhaust.GPUHours; math.Abs(got-tt.ratio) > 1e-9 {
+			t.Errorf("%q: median takes %v of the GPU-hours, want %v", tt.password, got, tt.ratio)
+		}
+		if got := c.Exhaust.Dollars / c.Exhaust.GPUHours; math.Abs(got-c.PricePerGPUHour) > 1e-9 {
+			t.Errorf("%q: $%v per GPU-hour, want %v", tt.password, got, c.PricePerGPUHour)
+		}
+	}
+}
+
+// TestBudgetChance checks the chance of cracking within the budget, which
+// testAnalyzer sets to 30 days of 8 devices at 5e8 guesses per second
+func TestBudgetChance(t *testing.T) {
+	tests := []struct {
+		password string
+		spec     string
+		budget   float64
+		want     string
+	}{
+		// 1.296e15 of the 1e16 brute-force guesses
+		{"Xv9Qm2Zr7Kp4Wt8J", "30d", 30 * 86400, "12.96% chance of being cracked within 30d on High-end GPU (8 devices)"},
+		// A budget far longer than the search
+		{"Xv9Qm2Zr7Kp4Wt8", "30d", 30 * 86400, "100% chance of being cracked within 30d on High-end GPU (8 devices)"},
+		// A ranked password not yet reached
+		{"correcthorsebatterystaple", "1d", 86400, "0% chance of being cracked within 1d on High-end GPU (8 devices)"},
+	}
+	for _, tt := range tests {
+		a := testAnalyzer(0)
+		a.commonChecks = nil
+		a.budgetSpec, a.budget = tt.spec, tt.budget
+
+		var text bytes.Buffer
+		report.WriteText(&text, a.analyze(tt.password))
+		if !strings.Contains(text.String(), "  "+tt.want+"\n") {
+			t.Errorf("%q: report does not contain %q:\n%s", tt.password, tt.want, text.String())
+		}
+	}
+}
+
+// TestScenarioSpeedsFollowCostParameters checks that the offline scenarios
+// scale the profile's speeds, measured at the default costs, to the chosen ones
+func TestScenarioSpeedsFollowCostParameters(t *testing.T) {
+	attacker, _ := profile.NewSet().Find("High-end GPU")
+	bcryptSpeed, _ := attacker.Speed("bcrypt")
+	md5Speed, _ := attacker.Speed("MD5")
+	pbkdf2Speed, _ := attacker.Speed("PBKDF2-HMAC-SHA256")
+
+	params := hash.DefaultParams()
+	params.BcryptCost += 2
+	params.PBKDF2Iterations = 1200000
+
+	tests := []struct {
+		hashName   string
+		slow, fast float64
+	}{
+		// Two more rounds of bcrypt take four times the work, whichever hash is selected
+		{"MD5", bcryptSpeed / 4, md5Speed},
+		{"bcrypt", bcryptSpeed / 4, md5Speed},
+		// Twice the default 600,000 iterations
+		{"PBKDF2-HMAC-SHA256", pbkdf2Speed / 2, md5Speed},
+	}
+	for _, tt := range tests {
+		a := newAnalyzer(&options{hashName: tt.hashName, system: "High-end GPU", params: params, profiles: profile.NewSet()})
+		speeds := map[string]float64{}
+		for _, s := range a.scenarios {
+			speeds[s.Name] = s.GuessesPerSecond
+		}
+		if speeds["offline-slow"] != tt.slow || speeds["offline-fast"] != tt.fast || speeds["offline-unsalted"] != tt.fast {
+			t.Errorf("%s: offline speeds %v, want %v slow and %v fast", tt.hashName, speeds, tt.slow, tt.fast)
+		}
+	}
+}
+
+// TestWhatIfShowsChange checks that the what-if line never compares two
+// times that read the same
+func TestWhatIfShowsChange(t *testing.T) {
+	for _, pw := range []string{"p@ssw0rd", "aaaaaa", "zq8vx2mk", "correcthorsebatterystaple"} {
+		a := testAnalyzer(0)
+		a.commonChecks = nil
+		r := a.analyze(pw)
+		if r.WhatIf.ExtraCharacters < whatIfExtraLength {
+			t.Errorf("%q: %d extra characters, want at least %d", pw, r.WhatIf.ExtraCharacters, whatIfExtraLength)
+		}
+
+		var text bytes.Buffer
+		report.WriteText(&text, r)
+		for _, line := range strings.Split(text.String(), "\n") {
+			if !strings.HasPrefix(line, "With ") {
+				continue
+			}
+			longer, shorter, _ := strings.Cut(strings.TrimSuffix(line[strings.Index(line, " take ")+6:], "."), " instead of ")
+			if longer == shorter {
+				t.Errorf("%q: %s", pw, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sharafdin/crackulator/common"
+	"github.com/sharafdin/crackulator/report"
+)
+
+// runBatch audits every password read from the batch input and prints a combined report
+func runBatch(opts *options) {
+	passwords, err := readBatch(opts.batch)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	a := newAnalyzer(opts)
+
+	// Hashing thousands of passwords with a slow algorithm would dominate the run
+	a.sampleHash = false
+
+	lists, err := opts.resolveLists()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	// Load the common lists once rather than rescanning them for every password
+	for _, list := range lists {
+		a.commonSources = append(a.commonSources, list.name)
+		wordlist, err := common.LoadLocal(list.path)
+		if err != nil {
+			exitWithError(err)
+		}
+		a.commonChecks = append(a.commonChecks, list.check(wordlist.Lookup))
+
+		// One rule attack over the list covers every password in the batch
+		if opts.ruleset != nil {
+			derived, err := common.MatchRulesLocal(list.path, opts.ruleset, passwords)
+			if err != nil {
+				exitWithError(err)
+			}
+			a.commonChecks = append(a.commonChecks, list.check(func(p string) common.Match { return derived[p] }))
+		}
+	}
+
+	results := make([]*report.Report, 0, len(passwords))
+	for _, p := range passwords {
+		result := a.analyze(p)
+		if !opts.showPassword {
+			result = report.Redact(result)
+		}
+		results = append(results, result)
+	}
+
+	batchReport := report.NewBatchReport(opts.hashName, opts.system, results)
+
+	if opts.format == "json" {
+		if err := report.WriteBatchJSON(os.Stdout, batchReport); err != nil {
+			exitWithError(err)
+		}
+		return
+	}
+
+	report.WriteBatchText(os.Stdout, batchReport)
+}
+
+// readBatch reads one password per line from a file, or from stdin when path is "-"
+func readBatch(path string) ([]string, error) {
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var passwords []string
+	reader := bufio.NewReader(input)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		// Only strip the line ending; surrounding spaces are part of the password
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			passwords = append(passwords, line)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return passwords, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sharafdin/crackulator/hash"
+)
+
+// cachedBenchmark returns this machine's speed for the selected algorithm and
+// parameters, reusing the cached measurement unless a refresh was requested.
+// It reports whether the result came from the cache.
+func cachedBenchmark(opts *options) (hash.CachedBenchmark, bool) {
+	cache := openBenchmarkCache()
+	if cache != nil && !opts.refreshBenchmark {
+		if cached, ok := cache.Lookup(opts.hashName, opts.params, opts.benchmarkOptions.Workers); ok {
+			return cached, true
+		}
+	}
+
+	if opts.format == "text" {
+		fmt.Println("\nRunning benchmark, please wait...")
+	}
+	result := hash.RunBenchmark(opts.hashName, opts.params, opts.benchmarkOptions)
+
+	if cache != nil {
+		cache.Store(result, opts.params)
+		if err := cache.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save benchmark cache: %v\n", err)
+		}
+	}
+	return hash.CachedBenchmark{
+		Parameters: opts.params.Describe(opts.hashName),
+		Workers:    result.Workers,
+		MeasuredAt: time.Now().UTC().Truncate(time.Second),
+		Result:     result,
+	}, false
+}
+
+// openBenchmarkCache loads the benchmark cache, warning and returning nil when it is unavailable
+func openBenchmarkCache() *hash.BenchmarkCache {
+	path, err := hash.DefaultBenchmarkCachePath()
+	if err == nil {
+		var cache *hash.BenchmarkCache
+		if cache, err = hash.LoadBenchmarkCache(path); err == nil {
+			return cache
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Warning: benchmark cache unavailable: %v\n", err)
+	return nil
+}
+
+// runBench benchmarks every hash algorithm, prints a comparison table and caches the results
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	hashNames := fs.String("hash", "", "Comma-separated algorithms to benchmark (default: all)")
+	var params hash.Params
+	var benchOpts hash.BenchmarkOptions
+	addParamFlags(fs, &params)
+	addBenchmarkFlags(fs, &benchOpts)
+	fs.Parse(args)
+
+	if err := params.Validate(); err != nil {
+		exitWithError(err)
+	}
+	if err := validateBenchmarkOptions(benchOpts); err != nil {
+		exitWithError(err)
+	}
+
+	names := hash.GetHashOptions()
+	if *hashNames != "" {
+		names = strings.Split(*hashNames, ",")
+		for i, name := range names {
+			names[i] = strings.TrimSpace(name)
+			if _, ok := hash.Types[names[i]]; !ok {
+				exitWithError(fmt.Errorf("unknown hash algorithm %q (available: %s)", names[i], strings.Join(hash.GetHashOptions(), ", ")))
+			}
+		}
+	}
+
+	cache := openBenchmarkCache()
+	machine := hash.CurrentMachine()
+	fmt.Printf("CPU: %s (%d cores), %s %s/%s\n\n", machine.CPUModel, machine.Cores, machine.GoVersion, machine.OS, machine.Arch)
+
+	results := make([]hash.BenchmarkResult, 0, len(names))
+	fastest := 0.0
+	for _, name := range names {
+		result := hash.RunBenchmark(name, params, benchOpts)
+		results = append(results, result)
+		fastest = max(fastest, result.HashesPerSecond)
+		if cache != nil {
+			cache.Store(result, params)
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ALGORITHM\tPARAMETERS\tHASHES/SEC\tPER CORE\tSTD DEV\tSLOWER THAN FASTEST")
+	for _, result := range results {
+		relative := "-"
+		if result.HashesPerSecond > 0 {
+			relative = fmt.Sprintf("%.0fx", fastest/result.HashesPerSecond)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", result.HashType, params.Describe(result.HashType),
+			formatRate(result.HashesPerSecond), formatRate(result.PerCore), formatRate(result.StdDev), relative)
+	}
+	w.Flush()
+
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			exitWithError(fmt.Errorf("saving benchmark cache: %w", err))
+		}
+		path, _ := hash.DefaultBenchmarkCachePath()
+		fmt.Printf("\nResults cached in %s\n", path)
+	}
+}
+
+// formatRate prints whole hashes per second, keeping decimals only for slow rates
+func formatRate(rate float64) string {
+	if rate >= 100 {
+		return fmt.Sprintf("%.0f", rate)
+	}
+	return fmt.Sprintf("%.2f", rate)
+}
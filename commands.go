@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sharafdin/crackulator/profile"
+)
+
+// commands maps subcommand names to their entry points
+var commands = map[string]func(args []string){
+	"profiles":       runProfiles,
+	"import-hashcat": runImportHashcat,
+	"bench":          runBench,
+	"index":          runIndex,
+	"serve-range":    runServeRange,
+}
+
+// runProfiles lists the available attacker profiles
+func runProfiles(args []string) {
+	fs := flag.NewFlagSet("profiles", flag.ExitOnError)
+	profileFile := fs.String("profiles", "", "YAML or JSON file of additional attacker profiles")
+	hashcatFile := fs.String("hashcat", "", "Saved \"hashcat -b\" output to add as a profile")
+	hashcatName := fs.String("hashcat-name", defaultHashcatName, "Name of the profile imported with --hashcat")
+	hashName := fs.String("hash", "", "Also show each profile's speed for this hash algorithm")
+	fs.Parse(args)
+
+	profiles, err := loadProfiles(*profileFile, *hashcatFile, *hashcatName)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *hashName != "" {
+		fmt.Fprintf(w, "NAME\tDEVICES\t%s HASHES/SEC\tDESCRIPTION\n", *hashName)
+	} else {
+		fmt.Fprintln(w, "NAME\tDEVICES\tALGORITHMS\tDESCRIPTION")
+	}
+
+	for _, p := range profiles.Profiles() {
+		devices := max(p.Devices, 1)
+		if *hashName != "" {
+			speed := "-"
+			if s, ok := p.Speed(*hashName); ok && s >= 1 {
+				speed = fmt.Sprintf("%.0f", s)
+			} else if ok {
+				speed = fmt.Sprintf("%.2f", s)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", p.Name, devices, speed, p.Description)
+		} else {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", p.Name, devices, len(p.Speeds), p.Description)
+		}
+	}
+	w.Flush()
+}
+
+// runImportHashcat converts saved "hashcat -b" output into a profile file written to stdout
+func runImportHashcat(args []string) {
+	fs := flag.NewFlagSet("import-hashcat", flag.ExitOnError)
+	name := fs.String("name", defaultHashcatName, "Name of the imported profile")
+	description := fs.String("description", "", "Description of the imported profile")
+	machines := fs.Int("devices", 1, "Number of machines like the benchmarked one")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: crackulator import-hashcat [flags] <hashcat-benchmark.txt>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	p, err := profile.LoadHashcat(fs.Arg(0), *name)
+	if err != nil {
+		exitWithError(err)
+	}
+	if *description != "" {
+		p.Description = *description
+	}
+	// Each machine has as many devices as the benchmarked one
+	p.Devices *= *machines
+
+	if err := profile.Write(os.Stdout, []*profile.Profile{p}); err != nil {
+		exitWithError(err)
+	}
+}
+
+// defaultHashcatName names profiles imported from hashcat output
+const defaultHashcatName = "hashcat benchmark"
+
+// loadProfiles returns the built-in profiles plus those in the profile file
+// and the hashcat benchmark output, if given
+func loadProfiles(path, hashcatPath, hashcatName string) (*profile.Set, error) {
+	profiles := profile.NewSet()
+	if path != "" {
+		if err := profiles.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if hashcatPath != "" {
+		p, err := profile.LoadHashcat(hashcatPath, hashcatName)
+		if err != nil {
+			return nil, err
+		}
+		profiles.Add(p)
+	}
+	return profiles, nil
+}
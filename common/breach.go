@@ -0,0 +1,87 @@
+package common
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBreachAPI is the Have I Been Pwned range API. A serve-range server
+// offers the same API from a downloaded copy of the corpus.
+const DefaultBreachAPI = "https://api.pwnedpasswords.com"
+
+// rangePrefixLength is the number of hex characters of the SHA-1 hash sent to
+// the range API. Every prefix is shared by hundreds of breached passwords, so
+// the server never learns which password was checked.
+const rangePrefixLength = 5
+
+// breachClient is used for range queries, which should never hang a run
+var breachClient = &http.Client{Timeout: 30 * time.Second}
+
+// CheckBreached returns how many times the password appears in the breach
+// corpus behind a range API, sending only the first five characters of its
+// SHA-1 hash. Zero means the password was not found.
+func CheckBreached(password, apiURL string) (int64, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:rangePrefixLength], digest[rangePrefixLength:]
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(apiURL, "/")+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, fmt.Errorf("building range request: %w", err)
+	}
+	// Padding hides the number of real suffixes from anyone watching the response size
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "crackulator")
+
+	resp, err := breachClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying range API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("range API returned status code %d", resp.StatusCode)
+	}
+
+	return findSuffix(resp.Body, suffix)
+}
+
+// findSuffix scans a range response for the hash suffix and returns its count.
+// Padding entries have a count of zero, so they never register as a match.
+func findSuffix(r io.Reader, suffix string) (int64, error) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("reading range response: %w", err)
+		}
+
+		if key, count, ok := parseRangeLine(line); ok && key == suffix {
+			return count, nil
+		}
+
+		if err == io.EOF {
+			return 0, nil
+		}
+	}
+}
+
+// parseRangeLine splits a "HASH:COUNT" line, upper-casing the hash
+func parseRangeLine(line string) (string, int64, bool) {
+	key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+	if !found {
+		return "", 0, false
+	}
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.ToUpper(key), count, true
+}
@@ -4,66 +4,121 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"strconv"
 	"strings"
 )
 
-// CheckLocal checks if a password exists in a common password list file
-func CheckLocal(password, filePath string) bool {
-	file, err := os.Open(filePath)
-	if err != nil {
-		fmt.Printf("Error opening file: %v\n", err)
-		return false
-	}
-	defer file.Close()
+// Match describes where a password was found in a common password list
+type Match struct {
+	Found bool
+	// Rank is the line the password first appears on, counting non-empty
+	// lines from one. In a list sorted by frequency it is the number of
+	// guesses an attacker working through the list needs.
+	Rank int64
+	// Count is the frequency given by a "password:count" line, or zero
+	Count int64
+	// List names the list the password was found in
+	List string
+	// Guesses is the number of candidates an attacker tries before reaching
+	// the password: its rank for an exact match, or its position in a rule
+	// attack over the list
+	Guesses int64
+	// Base and Rule are the list entry and mangling rule that derive the
+	// password; both are empty for an exact match
+	Base string
+	Rule string
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		if strings.TrimSpace(scanner.Text()) == password {
-			return true
+// Best picks the match an attacker reaches first: the fewest guesses, or
+// the first found when no guess number is known
+func Best(matches ...Match) Match {
+	var best Match
+	for _, m := range matches {
+		switch {
+		case !m.Found:
+		case !best.Found:
+			best = m
+		case m.Guesses > 0 && (best.Guesses == 0 || m.Guesses < best.Guesses):
+			best = m
 		}
 	}
+	return best
+}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Printf("Error reading file: %v\n", err)
+// splitCount splits a "password:count" line. Lines are also matched whole,
+// so passwords that merely end in a colon and digits are still found.
+func splitCount(line string) (string, int64, bool) {
+	i := strings.LastIndexByte(line, ':')
+	if i <= 0 || i == len(line)-1 {
+		return "", 0, false
 	}
-
-	return false
+	count, err := strconv.ParseInt(line[i+1:], 10, 64)
+	if err != nil || count < 0 {
+		return "", 0, false
+	}
+	return line[:i], count, true
 }
 
-// CheckOnline checks if a password exists in an online password list
-func CheckOnline(password, url string) bool {
-	// Get the content from the URL
-	resp, err := http.Get(url)
+// CheckLocal looks a password up in a common password list file or
+// directory, decompressing it as needed, or searches it with a binary
+// search if it is an index
+func CheckLocal(password, filePath string) Match {
+	if IsIndex(filePath) {
+		index, err := OpenIndex(filePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening index: %v\n", err)
+			return Match{}
+		}
+		defer index.Close()
+
+		match, err := index.Lookup(password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error searching index: %v\n", err)
+		}
+		match.List = filePath
+		return match
+	}
+
+	list, err := OpenList(filePath)
 	if err != nil {
-		fmt.Printf("Error fetching URL: %v\n", err)
-		return false
+		fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
+		return Match{}
 	}
-	defer resp.Body.Close()
+	defer list.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("Error: Received status code %d\n", resp.StatusCode)
-		return false
+	match, err := scanList(list, password)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+		return Match{}
 	}
+	match.List = filePath
+	return match
+}
 
-	// Read and check line by line without storing the entire file
-	reader := bufio.NewReader(resp.Body)
+// scanList reads a list line by line, without storing it, until the password
+// is found. Lines of any length are accepted.
+func scanList(r io.Reader, password string) (Match, error) {
+	reader := bufio.NewReader(r)
+	var rank int64
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil && err != io.EOF {
-			fmt.Printf("Error reading response: %v\n", err)
-			return false
+			return Match{}, err
 		}
 
-		if strings.TrimSpace(line) == password {
-			return true
+		if entry := strings.TrimSpace(line); entry != "" {
+			rank++
+			if entry == password {
+				return Match{Found: true, Rank: rank, Guesses: rank}, nil
+			}
+			if p, count, ok := splitEntry(entry); ok && p == password {
+				return Match{Found: true, Rank: rank, Count: count, Guesses: rank}, nil
+			}
 		}
 
 		if err == io.EOF {
-			break
+			return Match{}, nil
 		}
 	}
-
-	return false
-} 
\ No newline at end of file
+}
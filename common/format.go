@@ -0,0 +1,327 @@
+package common
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Compressed lists and archives are recognised by their magic bytes, so a
+// list needs no particular file name
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	zipMagic   = []byte("PK\x03\x04")
+)
+
+// A tar archive has no magic at its start; "ustar" sits at offset 257 of
+// the first header
+const (
+	tarMagic       = "ustar"
+	tarMagicOffset = 257
+)
+
+// maxNesting bounds how deeply compressed lists and archives may be nested,
+// such as a gzipped tar of zipped lists
+const maxNesting = 4
+
+// OpenList opens a wordlist for reading, decompressing it on the fly. The
+// path may be a plain, gzip or bzip2 file, a zip or tar archive, or a
+// directory; archives and directories read as the concatenation of the
+// lists they hold, in name order.
+func OpenList(path string) (io.ReadCloser, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening list: %w", err)
+	}
+	if info.IsDir() {
+		return openDir(path)
+	}
+	return openFile(path)
+}
+
+// DecodeList decompresses a wordlist read from a stream, such as a download
+// or stdin. A zip archive has its index at the end, so it is read into
+// memory first.
+func DecodeList(r io.Reader) (io.ReadCloser, error) {
+	return decode(r, 0)
+}
+
+// openFile opens one list file. A zip file is read through its central
+// directory rather than buffered.
+func openFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening list: %w", err)
+	}
+
+	magic := make([]byte, len(zipMagic))
+	if _, err := file.ReadAt(magic, 0); err == nil && bytes.Equal(magic, zipMagic) {
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		archive, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("reading zip %s: %w", path, err)
+		}
+		return &listReader{ReadCloser: zipEntries(archive, 0), file: file}, nil
+	}
+
+	r, err := decode(file, 0)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &listReader{ReadCloser: r, file: file}, nil
+}
+
+// openDir reads every list in a directory tree, skipping hidden files and
+// indexes
+func openDir(root string) (io.ReadCloser, error) {
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && !IsIndex(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	return &concatReader{next: func() (io.ReadCloser, error) {
+		if len(paths) == 0 {
+			return nil, io.EOF
+		}
+		path := paths[0]
+		paths = paths[1:]
+		return openFile(path)
+	}}, nil
+}
+
+// decode wraps r in the decompressor or archive reader its magic bytes call for
+func decode(r io.Reader, depth int) (io.ReadCloser, error) {
+	if depth > maxNesting {
+		return nil, errors.New("archives nested too deeply")
+	}
+
+	br := bufio.NewReaderSize(r, 64<<10)
+	// A short list simply has fewer bytes to peek at
+	magic, _ := br.Peek(tarMagicOffset + len(tarMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip: %w", err)
+		}
+		gz.Multistream(true)
+		return decode(gz, depth+1)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return decode(bzip2.NewReader(br), depth+1)
+	case bytes.HasPrefix(magic, xzMagic):
+		return nil, errors.New("xz-compressed lists are not supported; recompress the list with gzip or bzip2")
+	case bytes.HasPrefix(magic, zipMagic):
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading zip: %w", err)
+		}
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("reading zip: %w", err)
+		}
+		return zipEntries(archive, depth), nil
+	case len(magic) == tarMagicOffset+len(tarMagic) && string(magic[tarMagicOffset:]) == tarMagic:
+		return tarEntries(tar.NewReader(br), depth), nil
+	case bytes.HasPrefix(magic, []byte(indexFamily)):
+		return nil, errors.New("an index cannot be read as a list")
+	}
+	return io.NopCloser(br), nil
+}
+
+// zipEntries reads the files of a zip archive one after another
+func zipEntries(archive *zip.Reader, depth int) io.ReadCloser {
+	files := archive.File
+	return &concatReader{next: func() (io.ReadCloser, error) {
+		for len(files) > 0 {
+			f := files[0]
+			files = files[1:]
+			if f.FileInfo().IsDir() {
+				continue
+			}
+
+			rc, err := f.Open()
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+			}
+			r, err := decode(rc, depth+1)
+			if err != nil {
+				rc.Close()
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			return &listReader{ReadCloser: r, file: rc}, nil
+		}
+		return nil, io.EOF
+	}}
+}
+
+// tarEntries reads the regular files of a tar archive one after another
+func tarEntries(archive *tar.Reader, depth int) io.ReadCloser {
+	return &concatReader{next: func() (io.ReadCloser, error) {
+		for {
+			header, err := archive.Next()
+			if err != nil {
+				if err != io.EOF {
+					err = fmt.Errorf("reading tar: %w", err)
+				}
+				return nil, err
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			r, err := decode(archive, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", header.Name, err)
+			}
+			return r, nil
+		}
+	}}
+}
+
+// listReader closes the file under a decoded list along with the list
+type listReader struct {
+	io.ReadCloser
+	file io.Closer
+}
+
+func (l *listReader) Close() error {
+	err := l.ReadCloser.Close()
+	if fileErr := l.file.Close(); err == nil {
+		err = fileErr
+	}
+	return err
+}
+
+// concatReader reads a sequence of lists, opening each only when the one
+// before it is exhausted. A newline goes between lists, since the last line
+// of one may not end in one; the blank lines this can add are skipped by
+// every reader of lists.
+type concatReader struct {
+	next    func() (io.ReadCloser, error) // returns io.EOF after the last list
+	current io.ReadCloser
+}
+
+func (c *concatReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			list, err := c.next()
+			if err != nil {
+				return 0, err
+			}
+			c.current = list
+			if len(p) == 0 {
+				return 0, nil
+			}
+			p[0] = '\n'
+			return 1, nil
+		}
+
+		n, err := c.current.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		closeErr := c.current.Close()
+		c.current = nil
+		if closeErr != nil {
+			return n, closeErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (c *concatReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+	return c.current.Close()
+}
+
+// splitEntry extracts the password from a "password:count" line or a
+// "hash:plain" or "hash:salt:plain" line of a cracking potfile. Lines are
+// also matched whole, so passwords that merely contain a colon are still found.
+func splitEntry(line string) (string, int64, bool) {
+	if plain, ok := splitPotfile(line); ok {
+		return plain, 0, true
+	}
+	return splitCount(line)
+}
+
+// digestLengths are the hex lengths of the raw digests found in potfiles:
+// LM halves and MySQL323, MD5 and NTLM, SHA-1, SHA-224, SHA-256, SHA-384
+// and SHA-512
+var digestLengths = map[int]bool{16: true, 32: true, 40: true, 56: true, 64: true, 96: true, 128: true}
+
+// splitPotfile splits a "hash:plain" line, or a "hash:salt:plain" line of
+// a salted hash, where the hash is a hex digest or a crypt string such as
+// "$2b$10$...". hashcat writes plains holding a colon as "$HEX[...]", so the
+// plain follows the last colon; encoded plains are decoded.
+func splitPotfile(line string) (string, bool) {
+	i, j := strings.IndexByte(line, ':'), strings.LastIndexByte(line, ':')
+	if i <= 0 || j == len(line)-1 {
+		return "", false
+	}
+	hash, plain := line[:i], line[j+1:]
+
+	isDigest := digestLengths[len(hash)] && isHex(hash)
+	isCrypt := strings.HasPrefix(hash, "$") && strings.Count(hash, "$") >= 3
+	if !isDigest && !isCrypt {
+		return "", false
+	}
+
+	if strings.HasPrefix(plain, "$HEX[") && strings.HasSuffix(plain, "]") {
+		decoded, err := hex.DecodeString(plain[len("$HEX[") : len(plain)-1])
+		if err != nil || len(decoded) == 0 {
+			return "", false
+		}
+		plain = string(decoded)
+	}
+	return plain, true
+}
+
+// isHex reports whether s consists of hex digits only
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
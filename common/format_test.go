@@ -0,0 +1,210 @@
+package common
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bzip2List is testList compressed with bzip2, which Go can only decompress
+const bzip2List = "\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xd0\x1f\x79\x4c\x00\x00\x01\xc9\x80\x00\x10\x3f\x00\x24\x81\xd8\x80\x20\x00\x31\x4c\x00\x13\x42\x83\xd2\x64\x1f\xaa\x69\xbd\x46\x13\x65\xe0\x2c\xfc\x09\x42\x69\xa7\xc5\xdc\x91\x4e\x14\x24\x34\x07\xde\x53\x00"
+
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipData archives the files in the order given
+func zipData(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		w, err := archive.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(files[i+1]))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tarData archives the files in the order given
+func tarData(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		archive.WriteHeader(&tar.Header{Name: files[i], Mode: 0o644, Size: int64(len(files[i+1])), Typeflag: tar.TypeReg})
+		archive.Write([]byte(files[i+1]))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readLines returns the non-blank lines of a list, trimmed as the readers of
+// lists trim them
+func readLines(t *testing.T, r io.ReadCloser) []string {
+	t.Helper()
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestOpenListFormats(t *testing.T) {
+	want := []string{"123456", "password", "dragon"}
+	tests := map[string][]byte{
+		"plain":  []byte(testList),
+		"crlf":   []byte("123456\r\npassword\r\ndragon"),
+		"gzip":   gzipData(t, []byte(testList)),
+		"bzip2":  []byte(bzip2List),
+		"zip":    zipData(t, "a.txt", "123456\npassword", "dir/", "", "b.txt", "dragon\n"),
+		"tar":    tarData(t, "a.txt", "123456\n", "b.txt", "password\ndragon"),
+		"tar.gz": gzipData(t, tarData(t, "list.txt", testList)),
+		// A gzipped list inside a zip archive
+		"nested": zipData(t, "list.gz", string(gzipData(t, []byte(testList)))),
+	}
+	dir := t.TempDir()
+	for name, data := range tests {
+		// The format comes from the magic bytes, not the file name
+		path := filepath.Join(dir, name+".list")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := OpenList(path)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := readLines(t, r); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: OpenList read %q, want %q", name, got, want)
+		}
+
+		// A stream reads the same, zip archives included
+		r, err = DecodeList(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := readLines(t, r); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: DecodeList read %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestOpenListRejects(t *testing.T) {
+	tooDeep := []byte(testList)
+	for i := 0; i <= maxNesting+1; i++ {
+		tooDeep = gzipData(t, tooDeep)
+	}
+	tests := map[string][]byte{
+		"xz":     append([]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, "rest of the stream"...),
+		"index":  []byte(indexFamily + "\x00\x01"),
+		"nested": tooDeep,
+	}
+	for name, data := range tests {
+		if r, err := DecodeList(bytes.NewReader(data)); err == nil {
+			r.Close()
+			t.Errorf("%s: DecodeList succeeded", name)
+		}
+	}
+}
+
+// TestOpenListDirectory checks that the lists of a directory tree read as one
+// in name order, with a newline between lists whose last line has none
+func TestOpenListDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"a.txt":          []byte("one\ntwo"),
+		"b/c.txt.gz":     gzipData(t, []byte("three")),
+		"b/d/e.txt":      []byte("four\r\n"),
+		"f.zip":          zipData(t, "g.txt", "five"),
+		".hidden":        []byte("secret\n"),
+		".cache/old.txt": []byte("stale\n"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Indexes built beside the lists are skipped
+	if _, err := BuildIndex(strings.NewReader("indexed\n"), filepath.Join(dir, "b", "list.idx"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := OpenList(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"one", "two", "three", "four", "five"}
+	if got := readLines(t, r); !reflect.DeepEqual(got, want) {
+		t.Errorf("OpenList read %q, want %q", got, want)
+	}
+}
+
+func TestSplitEntry(t *testing.T) {
+	tests := []struct {
+		line     string
+		password string
+		count    int64
+		ok       bool
+	}{
+		{"dragon:42", "dragon", 42, true},
+		{"dragon", "", 0, false},
+		{"pass:word", "", 0, false},
+		{"5f4dcc3b5aa765d61d8327deb882cf99:password", "password", 0, true},
+		// Salted hashes carry the salt between the hash and the plain
+		{"e10adc3949ba59abbe56e057f20f883e:s4lt:123456", "123456", 0, true},
+		{"7c6a180b36896a0a8c02787eeafb0e4c:xy:z:letmein", "letmein", 0, true},
+		{"$2b$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy:monkey", "monkey", 0, true},
+		// hashcat encodes plains holding a colon
+		{"5f4dcc3b5aa765d61d8327deb882cf99:$HEX[613a62]", "a:b", 0, true},
+		{"5f4dcc3b5aa765d61d8327deb882cf99:s4lt:$HEX[613a62]", "a:b", 0, true},
+		{"5f4dcc3b5aa765d61d8327deb882cf99:$HEX[zz]", "", 0, false},
+		// An LM half is 16 hex digits
+		{"5f4dcc3b5aa765d6:abc:dragon", "dragon", 0, true},
+		// Neither a digest of a known length nor a crypt string
+		{"5f4dcc3b5aa765d61d8327deb882cf9:password", "", 0, false},
+		{"user:password", "", 0, false},
+		{"5f4dcc3b5aa765d61d8327deb882cf99:", "", 0, false},
+	}
+	for _, tt := range tests {
+		password, count, ok := splitEntry(tt.line)
+		if password != tt.password || count != tt.count || ok != tt.ok {
+			t.Errorf("splitEntry(%q) = %q, %d, %v; want %q, %d, %v", tt.line, password, count, ok, tt.password, tt.count, tt.ok)
+		}
+	}
+}
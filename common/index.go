@@ -0,0 +1,387 @@
+package common
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// An index file starts with indexMagic and the number of entries, followed
+// by one entry per distinct password, sorted by its 64-bit fingerprint: the
+// fingerprint, the rank of the line the password first appeared on and its
+// count column, or zero. A lookup is a binary search over the file, so it
+// needs O(log n) small reads and no memory beyond one entry.
+//
+// Fingerprints are the first 8 bytes of SHA-256, so a password that is not
+// in the list matches by accident with probability n/2^64 — about one in ten
+// billion for a list of a billion passwords.
+const (
+	indexMagic      = "CRKIDX2\n"
+	indexHeaderSize = len(indexMagic) + 8
+	indexEntrySize  = 24
+)
+
+// indexFamily is the magic prefix shared by every version of the index format
+const indexFamily = "CRKIDX"
+
+// DefaultIndexMemory is the memory used for sorting while building an index
+const DefaultIndexMemory = 256 << 20
+
+// IndexStats summarises a finished index build
+type IndexStats struct {
+	Lines   int64 // non-empty lines read
+	Entries int64 // distinct entries written
+	Runs    int   // sorted runs merged from temporary files
+}
+
+// Index answers membership queries against an index file
+type Index struct {
+	file    *os.File
+	entries int64
+}
+
+// indexEntry is one password in the index
+type indexEntry struct {
+	fingerprint uint64
+	rank        uint64
+	count       uint64
+}
+
+// compareEntries orders entries by fingerprint, then by rank so the first
+// occurrence of a password comes first
+func compareEntries(a, b indexEntry) int {
+	if c := cmp.Compare(a.fingerprint, b.fingerprint); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.rank, b.rank)
+}
+
+// fingerprint returns the 64-bit value stored in the index for a password
+func fingerprint(password string) uint64 {
+	sum := sha256.Sum256([]byte(password))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// IsIndex reports whether the file at path is an index rather than a plain wordlist
+func IsIndex(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false
+	}
+	return strings.HasPrefix(string(magic), indexFamily)
+}
+
+// OpenIndex opens an index file for lookups
+func OpenIndex(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening index: %w", err)
+	}
+
+	header := make([]byte, indexHeaderSize)
+	if _, err := io.ReadFull(file, header); err != nil || !strings.HasPrefix(string(header), indexFamily) {
+		file.Close()
+		return nil, fmt.Errorf("%s is not a wordlist index", path)
+	}
+	if string(header[:len(indexMagic)]) != indexMagic {
+		file.Close()
+		return nil, fmt.Errorf("index %s was built by an older version; rebuild it with \"crackulator index\"", path)
+	}
+	entries := int64(binary.BigEndian.Uint64(header[len(indexMagic):]))
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() != int64(indexHeaderSize)+entries*indexEntrySize {
+		file.Close()
+		return nil, fmt.Errorf("index %s is truncated or corrupt", path)
+	}
+
+	return &Index{file: file, entries: entries}, nil
+}
+
+// Lookup searches the index for the password, returning its rank and count when found
+func (idx *Index) Lookup(password string) (Match, error) {
+	target := fingerprint(password)
+	buf := make([]byte, indexEntrySize)
+
+	lo, hi := int64(0), idx.entries
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if _, err := idx.file.ReadAt(buf, int64(indexHeaderSize)+mid*indexEntrySize); err != nil {
+			return Match{}, fmt.Errorf("reading index: %w", err)
+		}
+		entry := decodeEntry(buf)
+		switch {
+		case entry.fingerprint == target:
+			rank := int64(entry.rank)
+			return Match{Found: true, Rank: rank, Count: int64(entry.count), Guesses: rank}, nil
+		case entry.fingerprint < target:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return Match{}, nil
+}
+
+// Len returns the number of distinct passwords in the index
+func (idx *Index) Len() int64 {
+	return idx.entries
+}
+
+// Close releases the index file
+func (idx *Index) Close() error {
+	return idx.file.Close()
+}
+
+// BuildIndex converts the wordlist read from r into an index file at
+// outputPath. Lines of any length are accepted, and "password:count" and
+// "hash:plain" lines are indexed under both the whole line and the password. Entries are sorted
+// in chunks that fit in memoryLimit bytes and spilled to temporary files,
+// which are then merged, so lists far larger than memory can be indexed.
+func BuildIndex(r io.Reader, outputPath string, memoryLimit int) (IndexStats, error) {
+	var stats IndexStats
+
+	chunkSize := max(memoryLimit/indexEntrySize, 1024)
+	chunk := make([]indexEntry, 0, chunkSize)
+	var runs []*os.File
+	defer func() {
+		for _, run := range runs {
+			run.Close()
+			os.Remove(run.Name())
+		}
+	}()
+
+	add := func(password string, count int64) error {
+		chunk = append(chunk, indexEntry{fingerprint: fingerprint(password), rank: uint64(stats.Lines), count: uint64(count)})
+		if len(chunk) < chunkSize {
+			return nil
+		}
+		run, err := writeRun(chunk)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		chunk = chunk[:0]
+		return nil
+	}
+
+	reader := bufio.NewReaderSize(r, 1<<20)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return stats, fmt.Errorf("reading list: %w", err)
+		}
+
+		if entry := strings.TrimSpace(line); entry != "" {
+			stats.Lines++
+			if err := add(entry, 0); err != nil {
+				return stats, err
+			}
+			if password, count, ok := splitEntry(entry); ok {
+				if err := add(password, count); err != nil {
+					return stats, err
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return stats, fmt.Errorf("creating index: %w", err)
+	}
+	defer out.Close()
+
+	// Leave room for the header, which needs the final entry count
+	w := bufio.NewWriterSize(out, 1<<20)
+	if _, err := w.Write(make([]byte, indexHeaderSize)); err != nil {
+		return stats, err
+	}
+
+	if len(runs) == 0 {
+		sortUnique(&chunk)
+		for _, entry := range chunk {
+			if err := writeEntry(w, entry); err != nil {
+				return stats, err
+			}
+		}
+		stats.Entries = int64(len(chunk))
+	} else {
+		if len(chunk) > 0 {
+			run, err := writeRun(chunk)
+			if err != nil {
+				return stats, err
+			}
+			runs = append(runs, run)
+		}
+		stats.Runs = len(runs)
+		if stats.Entries, err = mergeRuns(runs, w); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return stats, fmt.Errorf("writing index: %w", err)
+	}
+
+	header := make([]byte, indexHeaderSize)
+	copy(header, indexMagic)
+	binary.BigEndian.PutUint64(header[len(indexMagic):], uint64(stats.Entries))
+	if _, err := out.WriteAt(header, 0); err != nil {
+		return stats, fmt.Errorf("writing index: %w", err)
+	}
+
+	return stats, out.Close()
+}
+
+// sortUnique sorts the entries and keeps the first occurrence of each password
+func sortUnique(entries *[]indexEntry) {
+	slices.SortFunc(*entries, compareEntries)
+	*entries = slices.CompactFunc(*entries, func(a, b indexEntry) bool { return a.fingerprint == b.fingerprint })
+}
+
+// writeEntry appends one entry to the index
+func writeEntry(w io.Writer, entry indexEntry) error {
+	var buf [indexEntrySize]byte
+	binary.BigEndian.PutUint64(buf[0:], entry.fingerprint)
+	binary.BigEndian.PutUint64(buf[8:], entry.rank)
+	binary.BigEndian.PutUint64(buf[16:], entry.count)
+	if _, err := w.Write(buf[:]); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	return nil
+}
+
+// decodeEntry reads an entry written by writeEntry
+func decodeEntry(buf []byte) indexEntry {
+	return indexEntry{
+		fingerprint: binary.BigEndian.Uint64(buf[0:]),
+		rank:        binary.BigEndian.Uint64(buf[8:]),
+		count:       binary.BigEndian.Uint64(buf[16:]),
+	}
+}
+
+// writeRun sorts a chunk and writes it to a temporary file, rewound for reading
+func writeRun(chunk []indexEntry) (*os.File, error) {
+	sortUnique(&chunk)
+
+	run, err := os.CreateTemp("", "crackulator-index-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary file: %w", err)
+	}
+
+	w := bufio.NewWriterSize(run, 1<<20)
+	for _, entry := range chunk {
+		if err := writeEntry(w, entry); err != nil {
+			run.Close()
+			os.Remove(run.Name())
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		run.Close()
+		os.Remove(run.Name())
+		return nil, fmt.Errorf("writing temporary file: %w", err)
+	}
+	if _, err := run.Seek(0, io.SeekStart); err != nil {
+		run.Close()
+		os.Remove(run.Name())
+		return nil, err
+	}
+	return run, nil
+}
+
+// runReader yields the entries of one sorted run
+type runReader struct {
+	r       *bufio.Reader
+	current indexEntry
+}
+
+// next advances to the following entry, returning false at the end of the run
+func (rr *runReader) next() (bool, error) {
+	var buf [indexEntrySize]byte
+	if _, err := io.ReadFull(rr.r, buf[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading temporary file: %w", err)
+	}
+	rr.current = decodeEntry(buf[:])
+	return true, nil
+}
+
+// runHeap orders run readers by their current entry
+type runHeap []*runReader
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return compareEntries(h[i].current, h[j].current) < 0 }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x any)        { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
+
+// mergeRuns merges sorted runs into w, keeping only the first occurrence of
+// passwords that appear in more than one run, and returns the number written
+func mergeRuns(runs []*os.File, w io.Writer) (int64, error) {
+	h := &runHeap{}
+	for _, run := range runs {
+		rr := &runReader{r: bufio.NewReaderSize(run, 1<<16)}
+		ok, err := rr.next()
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			*h = append(*h, rr)
+		}
+	}
+	heap.Init(h)
+
+	var written int64
+	var last uint64
+	for h.Len() > 0 {
+		rr := (*h)[0]
+		if written == 0 || rr.current.fingerprint != last {
+			if err := writeEntry(w, rr.current); err != nil {
+				return written, err
+			}
+			written++
+			last = rr.current.fingerprint
+		}
+
+		ok, err := rr.next()
+		if err != nil {
+			return written, err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBuildIndexMergesRuns builds an index from more entries than fit in one
+// run, with passwords repeated across runs, and checks that the merged file is
+// sorted, holds each password once and keeps its first rank
+func TestBuildIndexMergesRuns(t *testing.T) {
+	var list strings.Builder
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&list, "pass%d\n", i)
+	}
+	for i := 0; i < 3000; i += 3 {
+		fmt.Fprintf(&list, "pass%d\n", i)
+	}
+	list.WriteString("\ndragon:42\n")
+
+	path := filepath.Join(t.TempDir(), "list.idx")
+	// A zero memory limit gives the smallest runs, of 1024 entries
+	stats, err := BuildIndex(strings.NewReader(list.String()), path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Lines != 4001 {
+		t.Errorf("Lines = %d, want 4001", stats.Lines)
+	}
+	if stats.Runs != 4 {
+		t.Errorf("Runs = %d, want 4", stats.Runs)
+	}
+	// Every "passN" once, plus "dragon:42" and "dragon"
+	if stats.Entries != 3002 {
+		t.Errorf("Entries = %d, want 3002", stats.Entries)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != indexHeaderSize+int(stats.Entries)*indexEntrySize {
+		t.Fatalf("index is %d bytes, want %d", len(data), indexHeaderSize+int(stats.Entries)*indexEntrySize)
+	}
+	var last uint64
+	for i := 0; i < int(stats.Entries); i++ {
+		entry := decodeEntry(data[indexHeaderSize+i*indexEntrySize:])
+		if i > 0 && entry.fingerprint <= last {
+			t.Fatalf("entry %d is out of order or repeated", i)
+		}
+		last = entry.fingerprint
+	}
+
+	idx, err := OpenIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if idx.Len() != stats.Entries {
+		t.Errorf("Len = %d, want %d", idx.Len(), stats.Entries)
+	}
+
+	tests := []struct {
+		password string
+		want     Match
+	}{
+		{"pass0", Match{Found: true, Rank: 1, Guesses: 1}},
+		{"pass2999", Match{Found: true, Rank: 3000, Guesses: 3000}},
+		// Repeated in a later run, but ranked by its first line
+		{"pass1500", Match{Found: true, Rank: 1501, Guesses: 1501}},
+		{"dragon", Match{Found: true, Rank: 4001, Count: 42, Guesses: 4001}},
+		{"dragon:42", Match{Found: true, Rank: 4001, Guesses: 4001}},
+		{"pass3000", Match{}},
+		{"", Match{}},
+	}
+	for _, tt := range tests {
+		got, err := idx.Lookup(tt.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, want %+v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestBuildIndexSingleRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.idx")
+	stats, err := BuildIndex(strings.NewReader("b\na\nb\nc\na\n"), path, DefaultIndexMemory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Runs != 0 || stats.Entries != 3 {
+		t.Errorf("got %d runs and %d entries, want 0 and 3", stats.Runs, stats.Entries)
+	}
+
+	idx, err := OpenIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	for password, rank := range map[string]int64{"b": 1, "a": 2, "c": 4} {
+		if got, _ := idx.Lookup(password); !got.Found || got.Rank != rank {
+			t.Errorf("Lookup(%q) = %+v, want rank %d", password, got, rank)
+		}
+	}
+}
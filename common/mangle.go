@@ -0,0 +1,126 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sharafdin/crackulator/rules"
+)
+
+// MatchRules runs a rule attack over the wordlist read from r, the way
+// hashcat does: every rule is applied to the first entry, then every rule to
+// the second, and so on. For each target password it returns the first entry
+// and rule that derive it, so the guess number is the candidate's position in
+// that attack. Reading stops once every target has been found.
+func MatchRules(r io.Reader, set *rules.Set, targets []string) (map[string]Match, error) {
+	results := map[string]Match{}
+	remaining := map[string]bool{}
+	lengths := map[int]bool{}
+	for _, t := range targets {
+		remaining[t] = true
+		lengths[len(t)] = true
+	}
+
+	// Most rules change a word's length by a fixed amount, so only the rules
+	// that can produce a target's length need to run on a word
+	candidates := map[int][]int{}
+	rulesFor := func(n int) []int {
+		if indexes, ok := candidates[n]; ok {
+			return indexes
+		}
+		var indexes []int
+		for i := range set.Rules {
+			if length := set.Rules[i].Length(n); length < 0 || lengths[length] {
+				indexes = append(indexes, i)
+			}
+		}
+		candidates[n] = indexes
+		return indexes
+	}
+
+	ruleCount := int64(len(set.Rules))
+	var rank int64
+	var buf []byte
+	reader := bufio.NewReader(r)
+	for len(remaining) > 0 {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if entry := strings.TrimSpace(line); entry != "" {
+			rank++
+			word := entry
+			if p, _, ok := splitEntry(entry); ok {
+				word = p
+			}
+
+			base := []byte(word)
+			for _, i := range rulesFor(len(base)) {
+				candidate, ok := set.Rules[i].Apply(buf, base)
+				buf = candidate
+				if !ok || !remaining[string(candidate)] {
+					continue
+				}
+
+				password := string(candidate)
+				results[password] = Match{
+					Found:   true,
+					Rank:    rank,
+					Guesses: (rank-1)*ruleCount + int64(i) + 1,
+					Base:    word,
+					Rule:    set.Rules[i].Text,
+				}
+				delete(remaining, password)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// MatchRulesLocal runs a rule attack over a wordlist file. Index files only
+// hold fingerprints, so they cannot be mangled.
+func MatchRulesLocal(filePath string, set *rules.Set, targets []string) (map[string]Match, error) {
+	if IsIndex(filePath) {
+		return nil, fmt.Errorf("rules need a plain wordlist, but %s is an index", filePath)
+	}
+
+	list, err := OpenList(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer list.Close()
+
+	results, err := MatchRules(list, set, targets)
+	if err != nil {
+		return nil, fmt.Errorf("reading list: %w", err)
+	}
+	return nameList(results, filePath), nil
+}
+
+// nameList records the list in every match of a rule attack
+func nameList(results map[string]Match, list string) map[string]Match {
+	for password, m := range results {
+		m.List = list
+		results[password] = m
+	}
+	return results
+}
+
+// CheckLocalRules looks for a mangled form of a password in a wordlist file
+func CheckLocalRules(password, filePath string, set *rules.Set) Match {
+	results, err := MatchRulesLocal(filePath, set, []string{password})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying rules: %v\n", err)
+		return Match{}
+	}
+	return results[password]
+}
@@ -0,0 +1,231 @@
+package common
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RangeEntry is one hash suffix of a range response and its breach count
+type RangeEntry struct {
+	Suffix string
+	Count  int64
+}
+
+// RangeSource answers range queries from a downloaded copy of the Pwned
+// Passwords corpus: either the single file ordered by hash, with one
+// "SHA1:COUNT" line per password, or a directory of per-prefix files such
+// as "5BAA6.txt" holding "SUFFIX:COUNT" lines, as the official downloader writes.
+type RangeSource struct {
+	file *os.File // sorted corpus file, nil for a directory
+	size int64
+	dir  string
+}
+
+// OpenRangeSource opens a corpus file or directory for range queries
+func OpenRangeSource(path string) (*RangeSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &RangeSource{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &RangeSource{file: file, size: info.Size()}, nil
+}
+
+// Close releases the corpus file
+func (s *RangeSource) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// Range returns the suffixes of every breached hash starting with the
+// five-character upper-case hex prefix
+func (s *RangeSource) Range(prefix string) ([]RangeEntry, error) {
+	if s.file == nil {
+		return s.rangeFromDir(prefix)
+	}
+	return s.rangeFromFile(prefix)
+}
+
+// rangeFromDir reads the per-prefix file; a missing file is an empty range
+func (s *RangeSource) rangeFromDir(prefix string) ([]RangeEntry, error) {
+	file, err := os.Open(filepath.Join(s.dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []RangeEntry
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if suffix, count, ok := parseRangeLine(line); ok {
+			entries = append(entries, RangeEntry{Suffix: suffix, Count: count})
+		}
+
+		if err == io.EOF {
+			return entries, nil
+		}
+	}
+}
+
+// rangeFromFile binary searches the sorted corpus for the first line with the
+// prefix and reads lines until the prefix changes
+func (s *RangeSource) rangeFromFile(prefix string) ([]RangeEntry, error) {
+	// Find the smallest offset whose following line sorts at or after the prefix
+	lo, hi := int64(0), s.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, line, err := s.lineAt(mid)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" || linePrefix(line) >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	start, _, err := s.lineAt(lo)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []RangeEntry
+	reader := bufio.NewReader(io.NewSectionReader(s.file, start, s.size-start))
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("reading corpus: %w", err)
+		}
+
+		hash, count, ok := parseRangeLine(line)
+		if ok {
+			if !strings.HasPrefix(hash, prefix) {
+				return entries, nil
+			}
+			entries = append(entries, RangeEntry{Suffix: hash[len(prefix):], Count: count})
+		}
+
+		if err == io.EOF {
+			return entries, nil
+		}
+	}
+}
+
+// lineAt returns the offset and text of the first line starting at or after
+// off, or an empty line at the end of the file
+func (s *RangeSource) lineAt(off int64) (int64, string, error) {
+	start := off
+	if off > 0 {
+		// Step back one byte so a line starting exactly at off is not skipped
+		start = off - 1
+	}
+	reader := bufio.NewReaderSize(io.NewSectionReader(s.file, start, s.size-start), 128)
+
+	if off > 0 {
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return s.size, "", nil
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("reading corpus: %w", err)
+		}
+		start += int64(len(skipped))
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", fmt.Errorf("reading corpus: %w", err)
+	}
+	return start, line, nil
+}
+
+// linePrefix returns the upper-cased range prefix of a corpus line
+func linePrefix(line string) string {
+	return strings.ToUpper(line[:min(rangePrefixLength, len(line))])
+}
+
+// isRangePrefix reports whether the prefix is five hex characters
+func isRangePrefix(prefix string) bool {
+	if len(prefix) != rangePrefixLength {
+		return false
+	}
+	for _, c := range prefix {
+		if !strings.ContainsRune("0123456789ABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// padRange adds random zero-count suffixes until the response holds 800 to
+// 1,000 entries, matching the padding of the public API
+func padRange(entries []RangeEntry) []RangeEntry {
+	target := 800 + rand.IntN(201)
+	const hexDigits = "0123456789ABCDEF"
+	for len(entries) < target {
+		suffix := make([]byte, 40-rangePrefixLength)
+		for i := range suffix {
+			suffix[i] = hexDigits[rand.IntN(len(hexDigits))]
+		}
+		entries = append(entries, RangeEntry{Suffix: string(suffix)})
+	}
+	slices.SortFunc(entries, func(a, b RangeEntry) int { return strings.Compare(a.Suffix, b.Suffix) })
+	return entries
+}
+
+// NewRangeHandler serves the range API of Have I Been Pwned at
+// /range/{prefix} from a local corpus
+func NewRangeHandler(source *RangeSource) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /range/{prefix}", func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.ToUpper(r.PathValue("prefix"))
+		if !isRangePrefix(prefix) {
+			http.Error(w, "The hash prefix was not in a valid format", http.StatusBadRequest)
+			return
+		}
+
+		entries, err := source.Range(prefix)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving range %s: %v\n", prefix, err)
+			http.Error(w, "Error reading the password corpus", http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("Add-Padding") == "true" {
+			entries = padRange(entries)
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		out := bufio.NewWriter(w)
+		for _, e := range entries {
+			fmt.Fprintf(out, "%s:%d\r\n", e.Suffix, e.Count)
+		}
+		out.Flush()
+	})
+	return mux
+}
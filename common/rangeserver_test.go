@@ -0,0 +1,154 @@
+package common
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testCorpus is a small corpus ordered by hash, with ranges before, after and
+// between the prefixes the tests ask for
+var testCorpus = strings.Join([]string{
+	"000000A1B2C3D4E5F60718293A4B5C6D7E8F9012:3",
+	"5BAA50000000000000000000000000000000000F:1",
+	"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824",
+	"5BAA6FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:2",
+	"5BAA70000000000000000000000000000000000F:7",
+	"FFFFF000000000000000000000000000000000FF:5",
+}, "\r\n") + "\r\n"
+
+// rangeServer serves testCorpus from a single file
+func rangeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	if err := os.WriteFile(path, []byte(testCorpus), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := OpenRangeSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { source.Close() })
+
+	server := httptest.NewServer(NewRangeHandler(source))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func getRange(t *testing.T, url string, padding bool) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if padding {
+		req.Header.Set("Add-Padding", "true")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestRangeHandler(t *testing.T) {
+	server := rangeServer(t)
+
+	tests := []struct {
+		name, prefix string
+		status       int
+		want         string
+	}{
+		{
+			name:   "hit",
+			prefix: "5BAA6",
+			status: http.StatusOK,
+			want:   "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:2\r\n",
+		},
+		{name: "lower case", prefix: "5baa6", status: http.StatusOK, want: "1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:2\r\n"},
+		{name: "first line", prefix: "00000", status: http.StatusOK, want: "0A1B2C3D4E5F60718293A4B5C6D7E8F9012:3\r\n"},
+		{name: "last line", prefix: "FFFFF", status: http.StatusOK, want: "000000000000000000000000000000000FF:5\r\n"},
+		{name: "miss", prefix: "5BAA8", status: http.StatusOK, want: ""},
+		{name: "miss between lines", prefix: "00001", status: http.StatusOK, want: ""},
+		{name: "miss past the end", prefix: "FFFFE", status: http.StatusOK, want: ""},
+		{name: "too short", prefix: "5BAA", status: http.StatusBadRequest},
+		{name: "not hex", prefix: "5BAAG", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := getRange(t, server.URL+"/range/"+tt.prefix, false)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+			if tt.status == http.StatusOK && body != tt.want {
+				t.Errorf("body = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangeHandlerPadding(t *testing.T) {
+	server := rangeServer(t)
+
+	status, body := getRange(t, server.URL+"/range/5BAA6", true)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n")
+	if len(lines) < 800 || len(lines) > 1000 {
+		t.Errorf("padded response has %d entries, want 800 to 1,000", len(lines))
+	}
+	var found int
+	for i, line := range lines {
+		suffix, count, ok := parseRangeLine(line)
+		if !ok || len(suffix) != 35 {
+			t.Fatalf("malformed line %q", line)
+		}
+		if i > 0 && line < lines[i-1] {
+			t.Errorf("padded response is not sorted at line %d", i)
+		}
+		if suffix == "1E4C9B93F3F0682250B6CF8331B7EE68FD8" && count == 9545824 {
+			found++
+		} else if suffix == "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF" && count == 2 {
+			found++
+		} else if count != 0 {
+			t.Errorf("padding entry %q has a non-zero count", line)
+		}
+	}
+	if found != 2 {
+		t.Errorf("padded response holds %d of the 2 real entries", found)
+	}
+}
+
+func TestRangeSourceDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte("1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := OpenRangeSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	entries, err := source.Range("5BAA6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0] != (RangeEntry{Suffix: "1E4C9B93F3F0682250B6CF8331B7EE68FD8", Count: 9545824}) {
+		t.Errorf("Range(5BAA6) = %+v", entries)
+	}
+	if entries, err := source.Range("00000"); err != nil || len(entries) != 0 {
+		t.Errorf("Range of a missing prefix file = %+v, %v; want an empty range", entries, err)
+	}
+}
@@ -0,0 +1,216 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Limits for downloading lists into the store
+const (
+	DefaultStoreMaxSize = 4 << 30
+	DefaultStoreTimeout = 10 * time.Minute
+)
+
+// pinPrefix introduces a SHA-256 pin in the fragment of a list URL. The
+// fragment is never sent to the server.
+const pinPrefix = "sha256="
+
+// Store keeps downloaded wordlists in a cache directory, so each list is
+// downloaded once and then checked locally. Later runs revalidate the copy
+// with the server's ETag and Last-Modified headers and fall back to it when
+// the server cannot be reached.
+type Store struct {
+	dir     string
+	maxSize int64
+	client  *http.Client
+}
+
+// storedList is the metadata kept next to each cached list
+type storedList struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// DefaultStoreDir returns the wordlist cache in the user's cache directory
+func DefaultStoreDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "crackulator", "wordlists"), nil
+}
+
+// NewStore returns a store in dir that refuses lists larger than maxSize
+// bytes and gives up on a download after timeout
+func NewStore(dir string, maxSize int64, timeout time.Duration) *Store {
+	return &Store{dir: dir, maxSize: maxSize, client: &http.Client{Timeout: timeout}}
+}
+
+// SplitPin separates a "#sha256=<hex>" pin from a list URL
+func SplitPin(rawURL string) (string, string, error) {
+	url, fragment, found := strings.Cut(rawURL, "#")
+	if !found || !strings.HasPrefix(fragment, pinPrefix) {
+		return rawURL, "", nil
+	}
+	pin := strings.ToLower(strings.TrimPrefix(fragment, pinPrefix))
+	if len(pin) != sha256.Size*2 || !isHex(pin) {
+		return "", "", fmt.Errorf("invalid SHA-256 pin %q in %s", pin, rawURL)
+	}
+	return url, pin, nil
+}
+
+// Fetch returns the path of a local copy of the list at rawURL, downloading
+// it when the cache has no copy or the server has a newer one. A URL ending
+// in "#sha256=<hex>" pins the list's contents: a download with any other
+// checksum is rejected.
+func (s *Store) Fetch(rawURL string) (string, error) {
+	url, pin, err := SplitPin(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	key := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(key[:16])
+	dataPath := filepath.Join(s.dir, name+".list")
+	metaPath := filepath.Join(s.dir, name+".json")
+
+	// A copy that does not match the pin is downloaded again
+	cached, ok := readStoredList(metaPath, dataPath, url, pin)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("User-Agent", "crackulator")
+	if ok {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if ok {
+			fmt.Fprintf(os.Stderr, "Warning: could not revalidate %s (%v); using the copy fetched %s\n", url, err, cached.Fetched.Format(time.DateOnly))
+			return dataPath, nil
+		}
+		return "", fmt.Errorf("fetching URL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		return dataPath, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching %s: received status code %d", url, resp.StatusCode)
+	}
+	if resp.ContentLength > s.maxSize {
+		return "", fmt.Errorf("%s is %d bytes, over the %d byte limit", url, resp.ContentLength, s.maxSize)
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", err
+	}
+	stored, err := s.download(resp.Body, dataPath, name, pin)
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", url, err)
+	}
+	stored.URL = url
+	stored.ETag = resp.Header.Get("ETag")
+	stored.LastModified = resp.Header.Get("Last-Modified")
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(metaPath, append(data, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	return dataPath, nil
+}
+
+// download writes body to dataPath through a temporary file, so a failed or
+// rejected download never replaces a good copy
+func (s *Store) download(body io.Reader, dataPath, name, pin string) (storedList, error) {
+	tmp, err := os.CreateTemp(s.dir, name+"-*.tmp")
+	if err != nil {
+		return storedList{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sum := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, sum), io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return storedList{}, err
+	}
+	if size > s.maxSize {
+		return storedList{}, fmt.Errorf("list exceeds the %d byte limit", s.maxSize)
+	}
+
+	digest := hex.EncodeToString(sum.Sum(nil))
+	if pin != "" && digest != pin {
+		return storedList{}, fmt.Errorf("SHA-256 is %s, but the list is pinned to %s", digest, pin)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return storedList{}, err
+	}
+	if err := os.Rename(tmp.Name(), dataPath); err != nil {
+		return storedList{}, err
+	}
+	return storedList{SHA256: digest, Size: size, Fetched: time.Now().UTC().Truncate(time.Second)}, nil
+}
+
+// readStoredList loads the metadata of a cached list, reporting false unless
+// the copy exists, belongs to url and has the size it was downloaded with.
+// A pinned copy is hashed again, since a changed file may keep its size.
+func readStoredList(metaPath, dataPath, url, pin string) (storedList, bool) {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return storedList{}, false
+	}
+	var stored storedList
+	if err := json.Unmarshal(data, &stored); err != nil || stored.URL != url {
+		return storedList{}, false
+	}
+
+	info, err := os.Stat(dataPath)
+	if err != nil || info.Size() != stored.Size {
+		return storedList{}, false
+	}
+	if pin != "" && (stored.SHA256 != pin || fileSHA256(dataPath) != pin) {
+		return storedList{}, false
+	}
+	return stored, true
+}
+
+// fileSHA256 returns the hex SHA-256 of a file, or "" if it cannot be read
+func fileSHA256(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
@@ -0,0 +1,234 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testList         = "123456\npassword\ndragon\n"
+	testETag         = `"v1"`
+	testLastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+)
+
+// listServer serves testList with an ETag and Last-Modified date, answering
+// a matching conditional request with 304, and counts full downloads
+func listServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == testETag || r.Header.Get("If-Modified-Since") == testLastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", testETag)
+		w.Header().Set("Last-Modified", testLastModified)
+		w.Write([]byte(testList))
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestStoreFetchDownloads(t *testing.T) {
+	server, downloads := listServer(t)
+	store := NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute)
+
+	path, err := store.Fetch(server.URL + "/list.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != testList {
+		t.Errorf("cached list = %q, want %q", got, testList)
+	}
+	if downloads.Load() != 1 {
+		t.Errorf("downloads = %d, want 1", downloads.Load())
+	}
+}
+
+func TestStoreFetchRevalidates(t *testing.T) {
+	for _, header := range []string{"ETag", "Last-Modified"} {
+		t.Run(header, func(t *testing.T) {
+			var conditional atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				validator := r.Header.Get("If-None-Match")
+				if header == "Last-Modified" {
+					validator = r.Header.Get("If-Modified-Since")
+				}
+				if validator != "" {
+					conditional.Add(1)
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				if header == "ETag" {
+					w.Header().Set("ETag", testETag)
+				} else {
+					w.Header().Set("Last-Modified", testLastModified)
+				}
+				w.Write([]byte(testList))
+			}))
+			defer server.Close()
+			store := NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute)
+
+			first, err := store.Fetch(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := store.Fetch(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first != second {
+				t.Errorf("revalidated path = %s, want %s", second, first)
+			}
+			if conditional.Load() != 1 {
+				t.Errorf("conditional requests = %d, want 1", conditional.Load())
+			}
+			if got := readFile(t, second); got != testList {
+				t.Errorf("cached list = %q, want %q", got, testList)
+			}
+		})
+	}
+}
+
+func TestStoreFetchPin(t *testing.T) {
+	server, _ := listServer(t)
+	sum := sha256.Sum256([]byte(testList))
+	digest := hex.EncodeToString(sum[:])
+
+	store := NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute)
+	if _, err := store.Fetch(server.URL + "#sha256=" + digest); err != nil {
+		t.Fatalf("matching pin: %v", err)
+	}
+
+	store = NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute)
+	wrong := strings.Repeat("0", len(digest))
+	if _, err := store.Fetch(server.URL + "#sha256=" + wrong); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("mismatched pin: err = %v, want a pin error", err)
+	}
+}
+
+// TestStoreFetchPinRehashesCache checks that a pinned copy changed on disk,
+// even to the same size, is downloaded again rather than trusted
+func TestStoreFetchPinRehashesCache(t *testing.T) {
+	server, downloads := listServer(t)
+	sum := sha256.Sum256([]byte(testList))
+	url := server.URL + "#sha256=" + hex.EncodeToString(sum[:])
+	store := NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute)
+
+	path, err := store.Fetch(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Fetch(url); err != nil {
+		t.Fatal(err)
+	}
+	if downloads.Load() != 1 {
+		t.Fatalf("downloads = %d, want 1 while the copy is intact", downloads.Load())
+	}
+
+	tampered := strings.Replace(testList, "dragon", "hunter", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Fetch(url); err != nil {
+		t.Fatal(err)
+	}
+	if downloads.Load() != 2 {
+		t.Errorf("downloads = %d, want 2 after the copy changed", downloads.Load())
+	}
+	if got := readFile(t, path); got != testList {
+		t.Errorf("cached list = %q, want %q", got, testList)
+	}
+
+	// Offline, a changed copy is not used in place of the pinned list
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+	if _, err := store.Fetch(url); err == nil {
+		t.Error("offline fetch returned a copy that no longer matches the pin")
+	}
+}
+
+func TestStoreFetchRejectsOversize(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{
+			name: "Content-Length",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(testList))
+			},
+			want: "over the",
+		},
+		{
+			name: "streamed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Flushing first sends the body chunked, without a length
+				w.(http.Flusher).Flush()
+				w.Write([]byte(testList))
+			},
+			want: "exceeds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			dir := t.TempDir()
+			store := NewStore(dir, 10, time.Minute)
+
+			_, err := store.Fetch(server.URL)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.want)
+			}
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 0 {
+				t.Errorf("store kept %d files after a rejected download", len(entries))
+			}
+		})
+	}
+}
+
+func TestStoreFetchFallsBackOffline(t *testing.T) {
+	server, _ := listServer(t)
+	store := NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute)
+
+	url := server.URL + "/list.txt"
+	cached, err := store.Fetch(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	path, err := store.Fetch(url)
+	if err != nil {
+		t.Fatalf("offline fetch: %v", err)
+	}
+	if path != cached {
+		t.Errorf("offline path = %s, want the cached %s", path, cached)
+	}
+
+	if _, err := NewStore(t.TempDir(), DefaultStoreMaxSize, time.Minute).Fetch(url); err == nil {
+		t.Error("offline fetch without a cached copy succeeded")
+	}
+}
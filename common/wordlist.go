@@ -0,0 +1,104 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Wordlist is a set of common passwords for repeated lookups, held in memory
+// or, for lists built with BuildIndex, searched on disk
+type Wordlist struct {
+	name    string
+	entries map[string]wordlistEntry
+	index   *Index
+}
+
+// wordlistEntry is the first line a password appears on and its count column
+type wordlistEntry struct {
+	rank  int64
+	count int64
+}
+
+// LoadLocal reads a common password list file or directory into memory,
+// decompressing it as needed, or opens it for lookups on disk if it is an index
+func LoadLocal(filePath string) (*Wordlist, error) {
+	if IsIndex(filePath) {
+		index, err := OpenIndex(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return &Wordlist{name: filePath, index: index}, nil
+	}
+
+	list, err := OpenList(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer list.Close()
+
+	return readWordlist(list, filePath)
+}
+
+// Lookup reports where the password appears in the list
+func (w *Wordlist) Lookup(password string) Match {
+	if w.index != nil {
+		match, err := w.index.Lookup(password)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error searching index: %v\n", err)
+		}
+		match.List = w.name
+		return match
+	}
+
+	entry, ok := w.entries[password]
+	if !ok {
+		return Match{}
+	}
+	return Match{Found: true, Rank: entry.rank, Count: entry.count, List: w.name, Guesses: entry.rank}
+}
+
+// Len returns the number of distinct passwords in the list
+func (w *Wordlist) Len() int {
+	if w.index != nil {
+		return int(w.index.Len())
+	}
+	return len(w.entries)
+}
+
+// readWordlist collects one password per line from r, keeping the first
+// line each appears on; "password:count" and "hash:plain" lines are stored
+// under both forms
+func readWordlist(r io.Reader, name string) (*Wordlist, error) {
+	w := &Wordlist{name: name, entries: map[string]wordlistEntry{}}
+	add := func(password string, entry wordlistEntry) {
+		if _, seen := w.entries[password]; !seen {
+			w.entries[password] = entry
+		}
+	}
+
+	var rank int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("reading list: %w", err)
+		}
+
+		if entry := strings.TrimSpace(line); entry != "" {
+			rank++
+			add(entry, wordlistEntry{rank: rank})
+			if password, count, ok := splitEntry(entry); ok {
+				add(password, wordlistEntry{rank: rank, count: count})
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return w, nil
+}
@@ -2,11 +2,23 @@ package hash
 
 import (
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"fmt"
+	"math/bits"
+	"os"
+	"strings"
+	"unicode/utf16"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/md4"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
 )
 
 // Function is a type for hashing function signatures
@@ -14,15 +26,75 @@ type Function func([]byte) []byte
 
 // Available hash types with their respective functions
 var Types = map[string]Function{
-	"MD5":     MD5,
-	"SHA-1":   SHA1,
-	"SHA-256": SHA256,
-	"bcrypt":  Bcrypt,
+	"MD5":                MD5,
+	"SHA-1":              SHA1,
+	"SHA-256":            SHA256,
+	"SHA-512":            SHA512,
+	"SHA3-256":           SHA3256,
+	"NTLM":               NTLM,
+	"md5crypt":           MD5Crypt,
+	"sha256crypt":        SHA256Crypt,
+	"sha512crypt":        SHA512Crypt,
+	"PBKDF2-HMAC-SHA256": PBKDF2SHA256,
+	"PBKDF2-HMAC-SHA512": PBKDF2SHA512,
+	"bcrypt":             Bcrypt,
+	"scrypt":             Scrypt,
+	"Argon2i":            Argon2i,
+	"Argon2id":           Argon2id,
 }
 
+// hashOptions lists the algorithms from fastest to slowest
+var hashOptions = []string{
+	"MD5", "SHA-1", "SHA-256", "SHA-512", "SHA3-256", "NTLM",
+	"md5crypt", "sha256crypt", "sha512crypt",
+	"PBKDF2-HMAC-SHA256", "PBKDF2-HMAC-SHA512",
+	"bcrypt", "scrypt", "Argon2i", "Argon2id",
+}
+
+// slowHashes are the algorithms designed to resist cracking through iteration or memory cost
+var slowHashes = map[string]bool{
+	"md5crypt":           true,
+	"sha256crypt":        true,
+	"sha512crypt":        true,
+	"PBKDF2-HMAC-SHA256": true,
+	"PBKDF2-HMAC-SHA512": true,
+	"bcrypt":             true,
+	"scrypt":             true,
+	"Argon2i":            true,
+	"Argon2id":           true,
+}
+
+// Default cost parameters, following the OWASP password storage recommendations
+const (
+	pbkdf2SHA256Iterations = 600000
+	pbkdf2SHA512Iterations = 210000
+	scryptLogN             = 15 // N = 32768
+	scryptR                = 8
+	scryptP                = 1
+	argon2Time             = 2
+	argon2Memory           = 19 * 1024 // KiB
+	argon2Threads          = 1
+	saltLength             = 16
+	keyLength              = 32
+)
+
 // GetHashOptions returns a list of available hash algorithm names
 func GetHashOptions() []string {
-	return []string{"MD5", "SHA-1", "SHA-256", "bcrypt"}
+	return append([]string(nil), hashOptions...)
+}
+
+// IsSlow reports whether the algorithm is a deliberately slow password hash
+func IsSlow(hashType string) bool {
+	return slowHashes[hashType]
+}
+
+// Format renders a hash for display: encoded password hashes such as
+// "$argon2id$..." are printed as they are, raw digests in hex
+func Format(digest []byte) string {
+	if strings.HasPrefix(string(digest), "$") {
+		return string(digest)
+	}
+	return fmt.Sprintf("%x", digest)
 }
 
 // MD5 implements MD5 hashing
@@ -43,13 +115,114 @@ func SHA256(data []byte) []byte {
 	return hash[:]
 }
 
+// SHA512 implements SHA-512 hashing
+func SHA512(data []byte) []byte {
+	hash := sha512.Sum512(data)
+	return hash[:]
+}
+
+// SHA3256 implements SHA3-256 hashing
+func SHA3256(data []byte) []byte {
+	hash := sha3.Sum256(data)
+	return hash[:]
+}
+
+// NTLM implements the Windows NT hash: MD4 of the UTF-16LE encoded password
+func NTLM(data []byte) []byte {
+	units := utf16.Encode([]rune(string(data)))
+	encoded := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		encoded = append(encoded, byte(u), byte(u>>8))
+	}
+
+	h := md4.New()
+	h.Write(encoded)
+	return h.Sum(nil)
+}
+
+// PBKDF2SHA256 implements PBKDF2-HMAC-SHA256 (a slow hash)
+func PBKDF2SHA256(data []byte) []byte {
+	return pbkdf2Hash(data, "PBKDF2-HMAC-SHA256", pbkdf2SHA256Iterations)
+}
+
+// PBKDF2SHA512 implements PBKDF2-HMAC-SHA512 (a slow hash)
+func PBKDF2SHA512(data []byte) []byte {
+	return pbkdf2Hash(data, "PBKDF2-HMAC-SHA512", pbkdf2SHA512Iterations)
+}
+
 // Bcrypt implements bcrypt hashing (a slow hash)
 func Bcrypt(data []byte) []byte {
 	// Use a cost of 10 which is the default
-	hash, err := bcrypt.GenerateFromPassword(data, 10)
+	return bcryptHash(data, bcrypt.DefaultCost)
+}
+
+// Scrypt implements scrypt hashing (a memory-hard hash)
+func Scrypt(data []byte) []byte {
+	return scryptHash(data, 1<<scryptLogN, scryptR, scryptP)
+}
+
+// Argon2i implements Argon2i hashing (a memory-hard hash)
+func Argon2i(data []byte) []byte {
+	return argon2Hash(data, "Argon2i", argon2Time, argon2Memory, argon2Threads)
+}
+
+// Argon2id implements Argon2id hashing (a memory-hard hash)
+func Argon2id(data []byte) []byte {
+	return argon2Hash(data, "Argon2id", argon2Time, argon2Memory, argon2Threads)
+}
+
+// bcryptHash hashes with the given bcrypt cost
+func bcryptHash(data []byte, cost int) []byte {
+	hash, err := bcrypt.GenerateFromPassword(data, cost)
 	if err != nil {
-		fmt.Printf("Error generating bcrypt hash: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error generating bcrypt hash: %v\n", err)
 		return []byte{}
 	}
 	return hash
-} 
\ No newline at end of file
+}
+
+// pbkdf2Hash derives a PBKDF2 key with the variant's HMAC and encodes it in PHC format
+func pbkdf2Hash(data []byte, hashType string, iterations int) []byte {
+	salt := randomSalt()
+	if hashType == "PBKDF2-HMAC-SHA512" {
+		key := pbkdf2.Key(data, salt, iterations, sha512.Size, sha512.New)
+		return encodePHC("pbkdf2-sha512", fmt.Sprint(iterations), salt, key)
+	}
+	key := pbkdf2.Key(data, salt, iterations, keyLength, sha256.New)
+	return encodePHC("pbkdf2-sha256", fmt.Sprint(iterations), salt, key)
+}
+
+// scryptHash derives an scrypt key and encodes it in PHC format
+func scryptHash(data []byte, n, r, p int) []byte {
+	salt := randomSalt()
+	key, err := scrypt.Key(data, salt, n, r, p, keyLength)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating scrypt hash: %v\n", err)
+		return []byte{}
+	}
+	params := fmt.Sprintf("ln=%d,r=%d,p=%d", bits.Len(uint(n))-1, r, p)
+	return encodePHC("scrypt", params, salt, key)
+}
+
+// argon2Hash derives an Argon2i or Argon2id key and encodes it in PHC format
+func argon2Hash(data []byte, hashType string, time, memory uint32, threads uint8) []byte {
+	salt := randomSalt()
+	params := fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, memory, time, threads)
+	if hashType == "Argon2i" {
+		return encodePHC("argon2i", params, salt, argon2.Key(data, salt, time, memory, threads, keyLength))
+	}
+	return encodePHC("argon2id", params, salt, argon2.IDKey(data, salt, time, memory, threads, keyLength))
+}
+
+// encodePHC encodes a derived key in the PHC string format
+func encodePHC(id, params string, salt, key []byte) []byte {
+	b64 := base64.RawStdEncoding
+	return []byte("$" + id + "$" + params + "$" + b64.EncodeToString(salt) + "$" + b64.EncodeToString(key))
+}
+
+// randomSalt returns a random salt for the key derivation functions
+func randomSalt() []byte {
+	salt := make([]byte, saltLength)
+	rand.Read(salt)
+	return salt
+}
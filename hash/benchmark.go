@@ -2,52 +2,145 @@ package hash
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
 	"time"
 )
 
+// BenchmarkOptions controls how long and how widely a benchmark runs
+type BenchmarkOptions struct {
+	Workers  int           // goroutines hashing in parallel; zero uses runtime.NumCPU()
+	Duration time.Duration // length of each measured round
+	Warmup   time.Duration // unmeasured run before the first round
+	Rounds   int           // number of measured rounds
+}
+
+// DefaultBenchmarkOptions returns the options used when none are given
+func DefaultBenchmarkOptions() BenchmarkOptions {
+	return BenchmarkOptions{
+		Workers:  runtime.NumCPU(),
+		Duration: time.Second,
+		Warmup:   250 * time.Millisecond,
+		Rounds:   3,
+	}
+}
+
 // BenchmarkResult holds the benchmark data
 type BenchmarkResult struct {
-	HashType   string
-	HashesPerSecond int64
+	HashType string `json:"hash_type"`
+	// HashesPerSecond is the median aggregate rate of all workers over the rounds
+	HashesPerSecond float64 `json:"hashes_per_second"`
+	// PerCore is the median rate of a single worker
+	PerCore float64 `json:"per_core"`
+	Workers int     `json:"workers"`
+	// Rounds holds the aggregate rate measured in each round
+	Rounds []float64 `json:"rounds"`
+	Min    float64   `json:"min"`
+	Median float64   `json:"median"`
+	StdDev float64   `json:"stddev"`
 }
 
-// RunBenchmark performs a benchmark test for the given hash type
-func RunBenchmark(hashType string) BenchmarkResult {
-	iterations := 100000 // 100k iterations for fast hashes
-	if hashType == "bcrypt" {
-		iterations = 10 // bcrypt is much slower, so use fewer iterations
+// RunBenchmark measures how many hashes per second this machine computes for
+// the given hash type and cost parameters. Every round runs the configured
+// number of workers for the configured duration; a worker always finishes
+// at least one hash, so slow hashes may run past the duration.
+func RunBenchmark(hashType string, params Params, opts BenchmarkOptions) BenchmarkResult {
+	defaults := DefaultBenchmarkOptions()
+	if opts.Workers <= 0 {
+		opts.Workers = defaults.Workers
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = defaults.Duration
+	}
+	if opts.Rounds <= 0 {
+		opts.Rounds = defaults.Rounds
 	}
 
+	result := BenchmarkResult{HashType: hashType, Workers: opts.Workers}
+
 	// Get the hash function
-	hashFunc := Types[hashType]
+	hashFunc := params.Function(hashType)
 	if hashFunc == nil {
-		return BenchmarkResult{
-			HashType:   hashType,
-			HashesPerSecond: 0,
-		}
-	}
-
-	fmt.Printf("Running benchmark for %s with %d iterations...\n", hashType, iterations)
-	
-	// Sample data to hash during benchmark
-	data := []byte("benchmark_password_sample")
-	
-	// Start timing
-	startTime := time.Now()
-	
-	// Run the hash function the specified number of times
-	for i := 0; i < iterations; i++ {
-		hashFunc(data)
-	}
-	
-	// Calculate elapsed time
-	elapsedTime := time.Since(startTime)
-	
-	// Calculate hashes per second
-	hashesPerSecond := int64(float64(iterations) / elapsedTime.Seconds())
-	
-	return BenchmarkResult{
-		HashType:   hashType,
-		HashesPerSecond: hashesPerSecond,
-	}
-} 
\ No newline at end of file
+		return result
+	}
+
+	fmt.Fprintf(os.Stderr, "Running benchmark for %s on %d workers, %d rounds of %s...\n", hashType, opts.Workers, opts.Rounds, opts.Duration)
+
+	if opts.Warmup > 0 {
+		benchmarkRound(hashFunc, opts.Workers, opts.Warmup)
+	}
+
+	for i := 0; i < opts.Rounds; i++ {
+		result.Rounds = append(result.Rounds, benchmarkRound(hashFunc, opts.Workers, opts.Duration))
+	}
+
+	result.Min, result.Median, result.StdDev = roundStatistics(result.Rounds)
+	result.HashesPerSecond = result.Median
+	result.PerCore = result.Median / float64(opts.Workers)
+
+	return result
+}
+
+// benchmarkRound runs the hash function on every worker until the duration
+// has passed and returns the combined hashes per second
+func benchmarkRound(hashFunc Function, workers int, duration time.Duration) float64 {
+	rates := make([]float64, workers)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	deadline := start.Add(duration)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			// Sample data to hash during benchmark, one copy per worker
+			data := []byte("benchmark_password_sample")
+
+			count := 0
+			for count == 0 || time.Now().Before(deadline) {
+				hashFunc(data)
+				count++
+			}
+			rates[w] = float64(count) / time.Since(start).Seconds()
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0.0
+	for _, rate := range rates {
+		total += rate
+	}
+	return total
+}
+
+// roundStatistics returns the minimum, median and standard deviation of the round rates
+func roundStatistics(rates []float64) (minimum, median, stddev float64) {
+	if len(rates) == 0 {
+		return 0, 0, 0
+	}
+
+	sorted := append([]float64(nil), rates...)
+	sort.Float64s(sorted)
+	minimum = sorted[0]
+	if n := len(sorted); n%2 == 1 {
+		median = sorted[n/2]
+	} else {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	mean := 0.0
+	for _, rate := range rates {
+		mean += rate
+	}
+	mean /= float64(len(rates))
+	for _, rate := range rates {
+		stddev += (rate - mean) * (rate - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(rates)))
+
+	return minimum, median, stddev
+}
@@ -0,0 +1,148 @@
+package hash
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// MachineInfo identifies the machine a benchmark was measured on
+type MachineInfo struct {
+	CPUModel  string `json:"cpu_model"`
+	Cores     int    `json:"cores"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// CurrentMachine describes the machine this process runs on
+func CurrentMachine() MachineInfo {
+	return MachineInfo{
+		CPUModel:  cpuModel(),
+		Cores:     runtime.NumCPU(),
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+}
+
+// cpuModel reads the processor name where the platform exposes it
+func cpuModel() string {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return "unknown"
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if found && strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return "unknown"
+}
+
+// CachedBenchmark is one benchmark result stored in the cache
+type CachedBenchmark struct {
+	Parameters string          `json:"parameters,omitempty"`
+	Workers    int             `json:"workers"`
+	MeasuredAt time.Time       `json:"measured_at"`
+	Result     BenchmarkResult `json:"result"`
+}
+
+// BenchmarkCache stores benchmark results so later runs can reuse them. The
+// results are only valid for the machine and Go version they were measured with.
+type BenchmarkCache struct {
+	Machine    MachineInfo       `json:"machine"`
+	Updated    time.Time         `json:"updated"`
+	Benchmarks []CachedBenchmark `json:"benchmarks"`
+
+	path string
+}
+
+// DefaultBenchmarkCachePath returns the cache file in the user's config directory
+func DefaultBenchmarkCachePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "crackulator", "benchmarks.json"), nil
+}
+
+// LoadBenchmarkCache reads the cache at path. A missing cache, or one written
+// on a different machine or Go version, yields an empty cache.
+func LoadBenchmarkCache(path string) (*BenchmarkCache, error) {
+	machine := CurrentMachine()
+	empty := &BenchmarkCache{Machine: machine, path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cache BenchmarkCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		// A corrupt cache is simply rebuilt
+		return empty, nil
+	}
+	if cache.Machine != machine {
+		return empty, nil
+	}
+
+	cache.path = path
+	return &cache, nil
+}
+
+// Lookup returns the cached result for a hash type measured with the given
+// parameters and number of workers
+func (c *BenchmarkCache) Lookup(hashType string, params Params, workers int) (CachedBenchmark, bool) {
+	parameters := params.Describe(hashType)
+	for _, b := range c.Benchmarks {
+		if b.Result.HashType == hashType && b.Parameters == parameters && b.Workers == workers {
+			return b, true
+		}
+	}
+	return CachedBenchmark{}, false
+}
+
+// Store records a result, replacing any earlier one for the same hash type,
+// parameters and number of workers
+func (c *BenchmarkCache) Store(result BenchmarkResult, params Params) {
+	entry := CachedBenchmark{
+		Parameters: params.Describe(result.HashType),
+		Workers:    result.Workers,
+		MeasuredAt: time.Now().UTC().Truncate(time.Second),
+		Result:     result,
+	}
+	c.Updated = entry.MeasuredAt
+
+	for i, b := range c.Benchmarks {
+		if b.Result.HashType == result.HashType && b.Parameters == entry.Parameters && b.Workers == entry.Workers {
+			c.Benchmarks[i] = entry
+			return
+		}
+	}
+	c.Benchmarks = append(c.Benchmarks, entry)
+}
+
+// Save writes the cache back to the file it was loaded from
+func (c *BenchmarkCache) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,244 @@
+package hash
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	stdhash "hash"
+	"strconv"
+)
+
+// Alphabet of the base64 variant used by Unix crypt
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Default rounds of the SHA-crypt schemes
+const shaCryptDefaultRounds = 5000
+
+// Byte order in which SHA-crypt and MD5-crypt encode their digests, three bytes at a time
+var (
+	sha256CryptOrder = [][3]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29},
+	}
+	sha512CryptOrder = [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+	md5CryptOrder = [][3]int{
+		{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5},
+	}
+)
+
+// MD5Crypt implements the $1$ MD5-based Unix crypt scheme with a random salt
+func MD5Crypt(data []byte) []byte {
+	return md5Crypt(data, randomCryptSalt(8))
+}
+
+// SHA256Crypt implements the $5$ SHA-256-based Unix crypt scheme with a random salt
+func SHA256Crypt(data []byte) []byte {
+	return shaCrypt(sha256.New, "$5$", sha256CryptOrder, data, randomCryptSalt(16), shaCryptDefaultRounds)
+}
+
+// SHA512Crypt implements the $6$ SHA-512-based Unix crypt scheme with a random salt
+func SHA512Crypt(data []byte) []byte {
+	return shaCrypt(sha512.New, "$6$", sha512CryptOrder, data, randomCryptSalt(16), shaCryptDefaultRounds)
+}
+
+// md5Crypt computes an MD5-crypt hash following the original FreeBSD implementation
+func md5Crypt(password, salt []byte) []byte {
+	const magic = "$1$"
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+
+	alternate := md5.New()
+	alternate.Write(password)
+	alternate.Write(salt)
+	alternate.Write(password)
+	final := alternate.Sum(nil)
+
+	ctx := md5.New()
+	ctx.Write(password)
+	ctx.Write([]byte(magic))
+	ctx.Write(salt)
+	for remaining := len(password); remaining > 0; remaining -= md5.Size {
+		ctx.Write(final[:min(remaining, md5.Size)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(password[:1])
+		}
+	}
+	final = ctx.Sum(nil)
+
+	// 1000 rounds to slow down brute force
+	for i := 0; i < 1000; i++ {
+		round := md5.New()
+		if i&1 != 0 {
+			round.Write(password)
+		} else {
+			round.Write(final)
+		}
+		if i%3 != 0 {
+			round.Write(salt)
+		}
+		if i%7 != 0 {
+			round.Write(password)
+		}
+		if i&1 != 0 {
+			round.Write(final)
+		} else {
+			round.Write(password)
+		}
+		final = round.Sum(nil)
+	}
+
+	out := []byte(magic)
+	out = append(out, salt...)
+	out = append(out, '$')
+	out = append(out, encodeCrypt(final, md5CryptOrder, [3]int{-1, -1, 11})...)
+	return out
+}
+
+// shaCrypt computes a SHA-crypt hash as specified by Ulrich Drepper
+func shaCrypt(newHash func() stdhash.Hash, magic string, order [][3]int, password, salt []byte, rounds int) []byte {
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+
+	// Digest B
+	alternate := newHash()
+	alternate.Write(password)
+	alternate.Write(salt)
+	alternate.Write(password)
+	altResult := alternate.Sum(nil)
+	size := len(altResult)
+
+	// Digest A
+	ctx := newHash()
+	ctx.Write(password)
+	ctx.Write(salt)
+	remaining := len(password)
+	for ; remaining > size; remaining -= size {
+		ctx.Write(altResult)
+	}
+	ctx.Write(altResult[:remaining])
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write(altResult)
+		} else {
+			ctx.Write(password)
+		}
+	}
+	result := ctx.Sum(nil)
+
+	// Sequence P
+	dp := newHash()
+	for i := 0; i < len(password); i++ {
+		dp.Write(password)
+	}
+	pSequence := repeatBytes(dp.Sum(nil), len(password))
+
+	// Sequence S
+	ds := newHash()
+	for i := 0; i < 16+int(result[0]); i++ {
+		ds.Write(salt)
+	}
+	sSequence := repeatBytes(ds.Sum(nil), len(salt))
+
+	// Rounds to slow down brute force
+	for i := 0; i < rounds; i++ {
+		round := newHash()
+		if i&1 != 0 {
+			round.Write(pSequence)
+		} else {
+			round.Write(result)
+		}
+		if i%3 != 0 {
+			round.Write(sSequence)
+		}
+		if i%7 != 0 {
+			round.Write(pSequence)
+		}
+		if i&1 != 0 {
+			round.Write(result)
+		} else {
+			round.Write(pSequence)
+		}
+		result = round.Sum(nil)
+	}
+
+	out := []byte(magic)
+	if rounds != shaCryptDefaultRounds {
+		out = append(out, "rounds="+strconv.Itoa(rounds)+"$"...)
+	}
+	out = append(out, salt...)
+	out = append(out, '$')
+
+	// The final group holds the bytes left over after the three-byte groups
+	last := [3]int{-1, size - 1, size - 2}
+	if size == sha512.Size {
+		last = [3]int{-1, -1, size - 1}
+	}
+	out = append(out, encodeCrypt(result, order, last)...)
+	return out
+}
+
+// encodeCrypt encodes a digest with the crypt base64 alphabet. Each group of
+// three bytes becomes four characters; the final group uses -1 for missing
+// bytes and produces one character more than the number of bytes it holds.
+func encodeCrypt(digest []byte, order [][3]int, last [3]int) []byte {
+	var out []byte
+	emit := func(group [3]int, n int) {
+		w := 0
+		for _, index := range group {
+			w <<= 8
+			if index >= 0 {
+				w |= int(digest[index])
+			}
+		}
+		for ; n > 0; n-- {
+			out = append(out, cryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+
+	for _, group := range order {
+		emit(group, 4)
+	}
+
+	present := 0
+	for _, index := range last {
+		if index >= 0 {
+			present++
+		}
+	}
+	emit(last, present+1)
+
+	return out
+}
+
+// repeatBytes repeats b until it is length bytes long
+func repeatBytes(b []byte, length int) []byte {
+	out := make([]byte, 0, length)
+	for len(out) < length {
+		out = append(out, b[:min(len(b), length-len(out))]...)
+	}
+	return out
+}
+
+// randomCryptSalt returns a random salt drawn from the crypt alphabet
+func randomCryptSalt(length int) []byte {
+	salt := make([]byte, length)
+	rand.Read(salt)
+	for i := range salt {
+		salt[i] = cryptAlphabet[int(salt[i])%len(cryptAlphabet)]
+	}
+	return salt
+}
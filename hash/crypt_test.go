@@ -0,0 +1,66 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"testing"
+)
+
+// Vectors from Ulrich Drepper's SHA-crypt specification and the glibc and
+// OpenSSL implementations of MD5-crypt
+func TestMD5Crypt(t *testing.T) {
+	tests := []struct {
+		password, salt, want string
+	}{
+		{"password", "saltsalt", "$1$saltsalt$qjXMvbEw8oaL.CzflDtaK/"},
+		{"This is just a test", "saltstri", "$1$saltstri$ufSt4fO66XiAxKBT488aF1"},
+	}
+	for _, tt := range tests {
+		if got := string(md5Crypt([]byte(tt.password), []byte(tt.salt))); got != tt.want {
+			t.Errorf("md5Crypt(%q, %q) = %s, want %s", tt.password, tt.salt, got, tt.want)
+		}
+	}
+}
+
+func TestSHACrypt(t *testing.T) {
+	const long = "a very much longer text to encrypt.  This one even stretches over morethan one line."
+	tests := []struct {
+		password, salt string
+		rounds         int
+		sha512         bool
+		want           string
+	}{
+		{"Hello world!", "saltstring", 5000, false, "$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5"},
+		{"Hello world!", "saltstringsaltstring", 10000, false, "$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA"},
+		{long, "anotherlongsaltstring", 1400, false, "$5$rounds=1400$anotherlongsalts$Rx.j8H.h8HjEDGomFU8bDkXm3XIUnzyxf12oP84Bnq1"},
+		{"Hello world!", "saltstring", 5000, true, "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"Hello world!", "saltstringsaltstring", 10000, true, "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{long, "anotherlongsaltstring", 1400, true, "$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1"},
+	}
+	for _, tt := range tests {
+		var got []byte
+		if tt.sha512 {
+			got = shaCrypt(sha512.New, "$6$", sha512CryptOrder, []byte(tt.password), []byte(tt.salt), tt.rounds)
+		} else {
+			got = shaCrypt(sha256.New, "$5$", sha256CryptOrder, []byte(tt.password), []byte(tt.salt), tt.rounds)
+		}
+		if string(got) != tt.want {
+			t.Errorf("shaCrypt(%q, %q, %d) = %s, want %s", tt.password, tt.salt, tt.rounds, got, tt.want)
+		}
+	}
+}
+
+func TestNTLM(t *testing.T) {
+	tests := []struct {
+		password, want string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"password", "8846f7eaee8fb117ad06bdd830b7586c"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%x", NTLM([]byte(tt.password))); got != tt.want {
+			t.Errorf("NTLM(%q) = %s, want %s", tt.password, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Params holds the cost parameters of the slow hash algorithms
+type Params struct {
+	BcryptCost int
+
+	// PBKDF2Iterations applies to both PBKDF2 variants; zero selects the
+	// recommended count for each (600,000 for SHA-256, 210,000 for SHA-512)
+	PBKDF2Iterations int
+
+	ScryptN int
+	ScryptR int
+	ScryptP int
+
+	Argon2Time    int
+	Argon2Memory  int // KiB
+	Argon2Threads int
+}
+
+// DefaultParams returns the parameters the speeds in the system tables were measured with
+func DefaultParams() Params {
+	return Params{
+		BcryptCost:    bcrypt.DefaultCost,
+		ScryptN:       1 << scryptLogN,
+		ScryptR:       scryptR,
+		ScryptP:       scryptP,
+		Argon2Time:    argon2Time,
+		Argon2Memory:  argon2Memory,
+		Argon2Threads: argon2Threads,
+	}
+}
+
+// Validate checks that every parameter is accepted by its algorithm
+func (p Params) Validate() error {
+	if p.BcryptCost < bcrypt.MinCost || p.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if p.PBKDF2Iterations < 0 {
+		return errors.New("PBKDF2 iterations must be positive")
+	}
+	if p.ScryptN <= 1 || p.ScryptN&(p.ScryptN-1) != 0 {
+		return errors.New("scrypt N must be a power of two greater than 1")
+	}
+	if p.ScryptR < 1 || p.ScryptP < 1 || uint64(p.ScryptR)*uint64(p.ScryptP) >= 1<<30 {
+		return errors.New("scrypt r and p must be positive and r*p below 2^30")
+	}
+	if p.Argon2Time < 1 || p.Argon2Time > math.MaxUint32 {
+		return errors.New("Argon2 time must be at least 1")
+	}
+	if p.Argon2Threads < 1 || p.Argon2Threads > math.MaxUint8 {
+		return fmt.Errorf("Argon2 threads must be between 1 and %d", math.MaxUint8)
+	}
+	if p.Argon2Memory < 8*p.Argon2Threads || p.Argon2Memory > math.MaxUint32 {
+		return errors.New("Argon2 memory must be at least 8 KiB per thread and below 4 TiB")
+	}
+	return nil
+}
+
+// Function returns the hash function for an algorithm configured with these parameters
+func (p Params) Function(hashType string) Function {
+	switch hashType {
+	case "bcrypt":
+		return func(data []byte) []byte { return bcryptHash(data, p.BcryptCost) }
+	case "PBKDF2-HMAC-SHA256", "PBKDF2-HMAC-SHA512":
+		iterations := p.pbkdf2Iterations(hashType)
+		return func(data []byte) []byte { return pbkdf2Hash(data, hashType, iterations) }
+	case "scrypt":
+		return func(data []byte) []byte { return scryptHash(data, p.ScryptN, p.ScryptR, p.ScryptP) }
+	case "Argon2i", "Argon2id":
+		return func(data []byte) []byte {
+			return argon2Hash(data, hashType, uint32(p.Argon2Time), uint32(p.Argon2Memory), uint8(p.Argon2Threads))
+		}
+	}
+	return Types[hashType]
+}
+
+// CostFactor returns how many times more work one hash takes with these
+// parameters than with the defaults. Hash speeds divide by this factor.
+func (p Params) CostFactor(hashType string) float64 {
+	d := DefaultParams()
+	switch hashType {
+	case "bcrypt":
+		return math.Pow(2, float64(p.BcryptCost-d.BcryptCost))
+	case "PBKDF2-HMAC-SHA256", "PBKDF2-HMAC-SHA512":
+		return float64(p.pbkdf2Iterations(hashType)) / float64(d.pbkdf2Iterations(hashType))
+	case "scrypt":
+		return float64(p.ScryptN) * float64(p.ScryptR) * float64(p.ScryptP) /
+			(float64(d.ScryptN) * float64(d.ScryptR) * float64(d.ScryptP))
+	case "Argon2i", "Argon2id":
+		// Threads split the work without reducing it, so only passes and memory count
+		return float64(p.Argon2Time) * float64(p.Argon2Memory) /
+			(float64(d.Argon2Time) * float64(d.Argon2Memory))
+	}
+	return 1
+}
+
+// Describe summarises the parameters that apply to an algorithm, or returns
+// "" for algorithms without tunable cost
+func (p Params) Describe(hashType string) string {
+	switch hashType {
+	case "bcrypt":
+		return fmt.Sprintf("cost=%d", p.BcryptCost)
+	case "PBKDF2-HMAC-SHA256", "PBKDF2-HMAC-SHA512":
+		return fmt.Sprintf("iterations=%d", p.pbkdf2Iterations(hashType))
+	case "scrypt":
+		return fmt.Sprintf("N=%d, r=%d, p=%d", p.ScryptN, p.ScryptR, p.ScryptP)
+	case "Argon2i", "Argon2id":
+		return fmt.Sprintf("t=%d, m=%d KiB, p=%d", p.Argon2Time, p.Argon2Memory, p.Argon2Threads)
+	}
+	return ""
+}
+
+// pbkdf2Iterations resolves the iteration count for a PBKDF2 variant
+func (p Params) pbkdf2Iterations(hashType string) int {
+	if p.PBKDF2Iterations > 0 {
+		return p.PBKDF2Iterations
+	}
+	if hashType == "PBKDF2-HMAC-SHA512" {
+		return pbkdf2SHA512Iterations
+	}
+	return pbkdf2SHA256Iterations
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sharafdin/crackulator/common"
+)
+
+// runIndex converts a wordlist into an index for fast lookups
+func runIndex(args []string) {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	memory := fs.Int("memory", common.DefaultIndexMemory>>20, "Memory in MiB used for sorting before spilling to temporary files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: crackulator index [flags] <wordlist|directory|-> <output.idx>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 || *memory < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var input io.ReadCloser
+	var err error
+	if path := fs.Arg(0); path == "-" {
+		input, err = common.DecodeList(os.Stdin)
+	} else {
+		input, err = common.OpenList(path)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	defer input.Close()
+
+	fmt.Fprintf(os.Stderr, "Building index %s...\n", fs.Arg(1))
+	stats, err := common.BuildIndex(input, fs.Arg(1), *memory<<20)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	fmt.Printf("Indexed %d distinct entries from %d lines", stats.Entries, stats.Lines)
+	if stats.Runs > 0 {
+		fmt.Printf(" (merged %d sorted runs)", stats.Runs)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,545 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sharafdin/crackulator/common"
+	"github.com/sharafdin/crackulator/hash"
+	"github.com/sharafdin/crackulator/password"
+	"github.com/sharafdin/crackulator/profile"
+	"github.com/sharafdin/crackulator/rules"
+	"github.com/sharafdin/crackulator/utils"
+)
+
+// options holds every choice that drives a single analysis run
+type options struct {
+	password         string
+	passwordEnv      string
+	passwordFD       int
+	confirm          bool
+	showPassword     bool
+	throttleSpec     string
+	throttle         password.Throttle
+	lockout          int64
+	percentileSpec   string
+	percentiles      []float64
+	budgetSpec       string
+	budget           float64 // seconds, zero when no budget was given
+	hashName         string
+	system           string
+	wordlists        stringList
+	wordlistURLs     stringList
+	wordlistCache    string
+	wordlistMaxSize  int64
+	wordlistTimeout  time.Duration
+	rulesName        string
+	ruleset          *rules.Set
+	policyName       string
+	policy           *password.Policy
+	context          stringList
+	breachCheck      bool
+	breachAPI        string
+	benchmark        bool
+	benchmarkOptions hash.BenchmarkOptions
+	refreshBenchmark bool
+	noInteractive    bool
+	format           string
+	batch            string
+	params           hash.Params
+	profileFile      string
+	hashcatFile      string
+	hashcatName      string
+	profiles         *profile.Set
+	pricingFile      string
+	instances        []*profile.Instance
+
+	// setFlags records which flags were given explicitly on the command line
+	setFlags map[string]bool
+}
+
+// parseOptions defines the command-line flags and parses them
+func parseOptions() *options {
+	opts := &options{setFlags: map[string]bool{}}
+
+	flag.StringVar(&opts.password, "p", "", "Password to analyze; it shows up in shell history and process lists, so prefer the prompt, --password-env or --password-fd")
+	flag.StringVar(&opts.passwordEnv, "password-env", "", "Read the password from this environment variable")
+	flag.IntVar(&opts.passwordFD, "password-fd", -1, "Read the password from the first line of this file descriptor")
+	flag.BoolVar(&opts.confirm, "confirm", false, "Ask for the password twice when prompting for it")
+	flag.BoolVar(&opts.showPassword, "show-password", false, "Show the password, the parts of it found as patterns and a sample hash in reports instead of masking them")
+	flag.StringVar(&opts.hashName, "hash", "", "Hash algorithm to simulate ("+strings.Join(hash.GetHashOptions(), ", ")+")")
+	flag.StringVar(&opts.system, "system", "", "Attacker profile to simulate (built in: "+strings.Join(profile.NewSet().Names(), ", ")+")")
+	flag.StringVar(&opts.throttleSpec, "throttle", "100/1h", "Rate limit of the login in the online throttled scenario, as <attempts>/<window>")
+	flag.Int64Var(&opts.lockout, "lockout", 0, "Failed logins that lock the account in the online throttled scenario (0: no lockout)")
+	flag.StringVar(&opts.percentileSpec, "percentiles", "10,50,90", "Comma-separated probabilities, in percent, to report the crack time for")
+	flag.StringVar(&opts.budgetSpec, "budget", "", "Report the chance of the password being cracked within this time, such as 30d, 12h or 1y")
+	flag.StringVar(&opts.profileFile, "profiles", "", "YAML or JSON file of additional attacker profiles")
+	flag.StringVar(&opts.pricingFile, "pricing", "", "YAML or JSON file of cloud instance prices, to report the cost of cracking the password")
+	flag.StringVar(&opts.hashcatFile, "hashcat", "", "Saved \"hashcat -b\" output to add as a profile")
+	flag.StringVar(&opts.hashcatName, "hashcat-name", defaultHashcatName, "Name of the profile imported with --hashcat")
+	flag.Var(&opts.wordlists, "wordlist", "Path to a common password list or directory of lists, plain or compressed, to check against (repeatable)")
+	flag.Var(&opts.wordlistURLs, "wordlist-url", "URL of a common password list to check against, downloaded once into the wordlist cache; append \"#sha256=<hex>\" to pin its checksum (repeatable)")
+	flag.StringVar(&opts.wordlistCache, "wordlist-cache", "", "Directory caching lists downloaded with --wordlist-url (default: the user cache directory)")
+	flag.Int64Var(&opts.wordlistMaxSize, "wordlist-max-size", common.DefaultStoreMaxSize>>20, "Largest list in MiB that --wordlist-url downloads")
+	flag.DurationVar(&opts.wordlistTimeout, "wordlist-timeout", common.DefaultStoreTimeout, "Time limit for downloading a list")
+	flag.StringVar(&opts.rulesName, "rules", "", "Also look for the password mangled from wordlist entries by a ruleset: built in ("+strings.Join(rules.BuiltinNames(), ", ")+") or a hashcat/John rule file")
+	flag.StringVar(&opts.policyName, "policy", "", "Check the password against a policy: built in ("+strings.Join(password.PolicyNames(), ", ")+") or a YAML or JSON policy file")
+	flag.Var(&opts.context, "context", "Word tied to the account, such as a user name or e-mail address, that the policy forbids in the password (repeatable)")
+	flag.BoolVar(&opts.breachCheck, "breach-check", false, "Look the password up in a breached-password range API, sending only a 5-character SHA-1 prefix")
+	flag.StringVar(&opts.breachAPI, "breach-api", common.DefaultBreachAPI, "Range API for --breach-check, such as a local serve-range server; implies --breach-check")
+	flag.BoolVar(&opts.benchmark, "benchmark", false, "Benchmark this machine's hash speed")
+	flag.BoolVar(&opts.refreshBenchmark, "refresh-benchmark", false, "Benchmark again instead of reusing the cached result")
+	addBenchmarkFlags(flag.CommandLine, &opts.benchmarkOptions)
+	flag.BoolVar(&opts.noInteractive, "no-interactive", false, "Never prompt; fail if a required choice is missing")
+	flag.StringVar(&opts.format, "format", "text", "Report format (text, json); json implies --no-interactive")
+	flag.StringVar(&opts.batch, "batch", "", "Audit every password in a file, one per line (\"-\" reads stdin); implies --no-interactive")
+	addParamFlags(flag.CommandLine, &opts.params)
+	flag.Parse()
+
+	flag.Visit(func(f *flag.Flag) {
+		opts.setFlags[f.Name] = true
+	})
+
+	if opts.isSet("breach-api") {
+		opts.breachCheck = true
+	}
+
+	// Prompts would corrupt machine-readable output, batch input may be
+	// stdin, and piped stdin holds the password rather than answers
+	if opts.format == "json" || opts.batch != "" || !utils.StdinIsTerminal() {
+		opts.noInteractive = true
+	}
+
+	return opts
+}
+
+// loadProfiles collects the built-in profiles, those in the profile file and
+// the one imported from hashcat output
+func (o *options) loadProfiles() error {
+	profiles, err := loadProfiles(o.profileFile, o.hashcatFile, o.hashcatName)
+	if err != nil {
+		return err
+	}
+	o.profiles = profiles
+	return nil
+}
+
+// loadPricing reads the cloud instance prices given with --pricing, if any.
+// Every instance must name a known profile, since its speeds are the GPUs'.
+func (o *options) loadPricing() error {
+	if o.pricingFile == "" {
+		return nil
+	}
+	instances, err := profile.LoadPricing(o.pricingFile)
+	if err != nil {
+		return err
+	}
+	for _, i := range instances {
+		if _, ok := o.profiles.Find(i.Profile); i.Profile != "" && !ok {
+			return fmt.Errorf("%s: instance %q uses unknown profile %q (available: %s)", o.pricingFile, i.Name, i.Profile, strings.Join(o.profiles.Names(), ", "))
+		}
+	}
+	o.instances = instances
+	return nil
+}
+
+// loadPassword reads the password from the environment variable or file
+// descriptor given by flags, or from stdin when it is not a terminal and no
+// prompt will be shown, so the password need not appear on the command line
+func (o *options) loadPassword() error {
+	sources := 0
+	for _, given := range []bool{o.isSet("p"), o.passwordEnv != "", o.passwordFD >= 0} {
+		if given {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("use only one of -p, --password-env and --password-fd")
+	}
+
+	switch {
+	case o.passwordEnv != "":
+		password, ok := os.LookupEnv(o.passwordEnv)
+		if !ok {
+			return fmt.Errorf("environment variable %s is not set", o.passwordEnv)
+		}
+		o.password = password
+	case o.passwordFD >= 0:
+		password, err := utils.ReadPasswordLine(os.NewFile(uintptr(o.passwordFD), "password-fd"))
+		if err != nil {
+			return fmt.Errorf("file descriptor %d: %w", o.passwordFD, err)
+		}
+		o.password = password
+	case sources == 0 && o.batch == "" && o.noInteractive && !utils.StdinIsTerminal():
+		password, err := utils.ReadPasswordLine(os.Stdin)
+		if err != nil {
+			return err
+		}
+		o.password = password
+	}
+	return nil
+}
+
+// loadRules reads the ruleset given with --rules, if any
+func (o *options) loadRules() error {
+	if o.rulesName == "" {
+		return nil
+	}
+	set, err := rules.Load(o.rulesName)
+	if err != nil {
+		return err
+	}
+	if set.Skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %d rules with unsupported functions in %s\n", set.Skipped, set.Name)
+	}
+	o.ruleset = set
+	return nil
+}
+
+// loadPolicy reads the policy given with --policy, if any
+func (o *options) loadPolicy() error {
+	if o.policyName == "" {
+		return nil
+	}
+	policy, err := password.LoadPolicy(o.policyName)
+	if err != nil {
+		return err
+	}
+	o.policy = policy
+	return nil
+}
+
+// addParamFlags defines the cost parameters of the slow hashes, defaulting to
+// the values the profile speeds assume
+func addParamFlags(fs *flag.FlagSet, params *hash.Params) {
+	defaults := hash.DefaultParams()
+	fs.IntVar(&params.BcryptCost, "bcrypt-cost", defaults.BcryptCost, "bcrypt cost factor")
+	fs.IntVar(&params.PBKDF2Iterations, "pbkdf2-iterations", 0, "PBKDF2 iteration count (default 600000 for SHA-256, 210000 for SHA-512)")
+	fs.IntVar(&params.ScryptN, "scrypt-n", defaults.ScryptN, "scrypt CPU/memory cost N (a power of two)")
+	fs.IntVar(&params.ScryptR, "scrypt-r", defaults.ScryptR, "scrypt block size r")
+	fs.IntVar(&params.ScryptP, "scrypt-p", defaults.ScryptP, "scrypt parallelism p")
+	fs.IntVar(&params.Argon2Time, "argon2-time", defaults.Argon2Time, "Argon2 passes over memory")
+	fs.IntVar(&params.Argon2Memory, "argon2-memory", defaults.Argon2Memory, "Argon2 memory in KiB")
+	fs.IntVar(&params.Argon2Threads, "argon2-threads", defaults.Argon2Threads, "Argon2 parallelism")
+}
+
+// addBenchmarkFlags defines how the benchmark runs
+func addBenchmarkFlags(fs *flag.FlagSet, opts *hash.BenchmarkOptions) {
+	defaults := hash.DefaultBenchmarkOptions()
+	fs.IntVar(&opts.Workers, "benchmark-workers", defaults.Workers, "Goroutines hashing in parallel during the benchmark")
+	fs.DurationVar(&opts.Duration, "benchmark-duration", defaults.Duration, "Length of each benchmark round")
+	fs.IntVar(&opts.Rounds, "benchmark-rounds", defaults.Rounds, "Number of measured benchmark rounds")
+	opts.Warmup = defaults.Warmup
+}
+
+// validateBenchmarkOptions checks the benchmark flags
+func validateBenchmarkOptions(opts hash.BenchmarkOptions) error {
+	if opts.Workers < 1 || opts.Rounds < 1 || opts.Duration <= 0 {
+		return errors.New("benchmark workers, rounds and duration must be positive")
+	}
+	return nil
+}
+
+// stringList collects the values of a flag that may be repeated
+type stringList []string
+
+// String implements flag.Value
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set implements flag.Value
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parsePercentiles reads a comma-separated list of percentages
+func parsePercentiles(spec string) ([]float64, error) {
+	var percentiles []float64
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(field, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q: want a number above 0 and up to 100", field)
+		}
+		percentiles = append(percentiles, p)
+	}
+	return percentiles, nil
+}
+
+// budgetUnits are the units a budget may use beyond those of time.ParseDuration
+var budgetUnits = map[string]float64{"d": 86400, "w": 7 * 86400, "y": 31557600}
+
+// parseBudget reads a time budget in seconds, written as a Go duration or a
+// number of days, weeks or years such as "30d"
+func parseBudget(spec string) (float64, error) {
+	if unit, ok := budgetUnits[spec[len(spec)-1:]]; ok {
+		n, err := strconv.ParseFloat(spec[:len(spec)-1], 64)
+		if err == nil && n > 0 && !math.IsInf(n, 0) {
+			return n * unit, nil
+		}
+	} else if d, err := time.ParseDuration(spec); err == nil && d > 0 {
+		return d.Seconds(), nil
+	}
+	return 0, fmt.Errorf("invalid budget %q: want a positive duration such as 30d, 12h or 1y", spec)
+}
+
+// isSet reports whether the named flag was given on the command line
+func (o *options) isSet(name string) bool {
+	return o.setFlags[name]
+}
+
+// checkCommon reports whether a common password check was requested
+func (o *options) checkCommon() bool {
+	return len(o.wordlists) > 0 || len(o.wordlistURLs) > 0
+}
+
+// listSource is a common password list to check: the name it is reported
+// under and the local file or directory holding it
+type listSource struct {
+	name string
+	path string
+}
+
+// check reports the matches of a lookup under the list's name rather than
+// the local path it was read from
+func (l listSource) check(lookup func(string) common.Match) func(string) common.Match {
+	return func(password string) common.Match {
+		m := lookup(password)
+		if m.Found {
+			m.List = l.name
+		}
+		return m
+	}
+}
+
+// resolveLists returns every common password list to check, files first,
+// downloading the URLs into the wordlist cache
+func (o *options) resolveLists() ([]listSource, error) {
+	var lists []listSource
+	for _, path := range o.wordlists {
+		lists = append(lists, listSource{name: path, path: path})
+	}
+	if len(o.wordlistURLs) == 0 {
+		return lists, nil
+	}
+
+	dir := o.wordlistCache
+	if dir == "" {
+		var err error
+		if dir, err = common.DefaultStoreDir(); err != nil {
+			return nil, fmt.Errorf("locating the wordlist cache: %w", err)
+		}
+	}
+	store := common.NewStore(dir, o.wordlistMaxSize<<20, o.wordlistTimeout)
+	for _, url := range o.wordlistURLs {
+		path, err := store.Fetch(url)
+		if err != nil {
+			return nil, err
+		}
+		name, _, _ := common.SplitPin(url)
+		lists = append(lists, listSource{name: name, path: path})
+	}
+	return lists, nil
+}
+
+// validate checks the values given on the command line
+func (o *options) validate() error {
+	if o.format != "text" && o.format != "json" {
+		return fmt.Errorf("unknown report format %q (available: text, json)", o.format)
+	}
+	if o.batch != "" && o.password != "" {
+		return errors.New("use either a single password or --batch, not both")
+	}
+	if o.ruleset != nil {
+		for _, path := range o.wordlists {
+			if common.IsIndex(path) {
+				return fmt.Errorf("--rules needs plain wordlists, but %s is an index", path)
+			}
+		}
+	}
+	if o.hashName != "" {
+		if _, ok := hash.Types[o.hashName]; !ok {
+			return fmt.Errorf("unknown hash algorithm %q (available: %s)", o.hashName, strings.Join(hash.GetHashOptions(), ", "))
+		}
+	}
+	if err := validateBenchmarkOptions(o.benchmarkOptions); err != nil {
+		return err
+	}
+	if o.wordlistMaxSize < 1 || o.wordlistTimeout <= 0 {
+		return errors.New("--wordlist-max-size and --wordlist-timeout must be positive")
+	}
+	for _, url := range o.wordlistURLs {
+		if _, _, err := common.SplitPin(url); err != nil {
+			return err
+		}
+	}
+	if err := o.params.Validate(); err != nil {
+		return err
+	}
+	throttle, err := password.ParseThrottle(o.throttleSpec)
+	if err != nil {
+		return fmt.Errorf("--throttle: %w", err)
+	}
+	o.throttle = throttle
+	if o.lockout < 0 {
+		return errors.New("--lockout must not be negative")
+	}
+	if o.percentiles, err = parsePercentiles(o.percentileSpec); err != nil {
+		return err
+	}
+	if o.budgetSpec != "" {
+		if o.budget, err = parseBudget(o.budgetSpec); err != nil {
+			return err
+		}
+	}
+	if o.system != "" {
+		p, ok := o.profiles.Find(o.system)
+		if !ok {
+			return fmt.Errorf("unknown system %q (available: %s)", o.system, strings.Join(o.profiles.Names(), ", "))
+		}
+		if _, ok := p.Speed(o.hashName); o.hashName != "" && !ok {
+			return fmt.Errorf("profile %q has no speed for %s", o.system, o.hashName)
+		}
+	}
+
+	if !o.noInteractive {
+		return nil
+	}
+
+	// Without prompts every required choice has to come from a flag
+	var missing []string
+	if o.password == "" && o.batch == "" {
+		missing = append(missing, "a password (-p, --password-env, --password-fd or stdin)")
+	}
+	if o.hashName == "" {
+		missing = append(missing, "--hash")
+	}
+	if o.system == "" {
+		missing = append(missing, "--system")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s required in non-interactive mode", strings.Join(missing, ", "))
+	}
+	if o.ruleset != nil && !o.checkCommon() {
+		return errors.New("--rules needs --wordlist or --wordlist-url")
+	}
+
+	return nil
+}
+
+// prompt asks the user for every choice not already given as a flag
+func (o *options) prompt() error {
+	// 1. Get password input
+	if o.password == "" {
+		password, err := utils.GetPasswordInput(o.confirm)
+		if err != nil {
+			return err
+		}
+		o.password = password
+	}
+
+	// 2. Common password check
+	if !o.checkCommon() && !o.breachCheck {
+		check, err := utils.AskYesNo("Do you want to check against common passwords? (y/n)")
+		if err != nil {
+			return err
+		}
+		if check {
+			checkType, err := utils.AskOption("Choose check type:", []string{"Local file", "Online URL", "Breached passwords (range API)"})
+			if err != nil {
+				return err
+			}
+
+			switch checkType {
+			case "Local file":
+				path, err := utils.AskInput("Enter path to password file:")
+				if err != nil {
+					return err
+				}
+				o.wordlists = append(o.wordlists, path)
+			case "Online URL":
+				url, err := utils.AskInput("Enter URL of password list:")
+				if err != nil {
+					return err
+				}
+				o.wordlistURLs = append(o.wordlistURLs, url)
+			default:
+				o.breachCheck = true
+			}
+		}
+	}
+
+	// 3. Hash algorithm selection
+	if o.hashName == "" {
+		fmt.Println("\n🔐 Hash Algorithm Selection:")
+		fmt.Println("Different hash algorithms have different cracking speeds.")
+		fmt.Println("Fast hashes (MD5, SHA-1, SHA-256, SHA-512, SHA3-256, NTLM) are quicker to crack.")
+		fmt.Println("Slow hashes (crypt schemes, PBKDF2, bcrypt) are designed to be more resistant to cracking attempts.")
+		fmt.Println("Memory-hard hashes (scrypt, Argon2) also resist GPU cracking.")
+
+		hashName, err := utils.AskOption("Select a hash algorithm:", o.supportedHashes())
+		if err != nil {
+			return err
+		}
+		o.hashName = hashName
+	}
+
+	// 4. System selection
+	if o.system == "" {
+		fmt.Println("\n💻 System Selection:")
+		fmt.Println("Select the type of system you want to simulate for password cracking:")
+		system, err := utils.AskOption("Choose system type:", o.supportingProfiles())
+		if err != nil {
+			return err
+		}
+		o.system = system
+	}
+
+	// 5. Benchmarking option
+	if !o.isSet("benchmark") {
+		benchmark, err := utils.AskYesNo("\nDo you want to benchmark your actual system's hash speed? (y/n)")
+		if err != nil {
+			return err
+		}
+		o.benchmark = benchmark
+	}
+	return nil
+}
+
+// supportingProfiles lists the profiles that have a speed for the selected hash algorithm
+func (o *options) supportingProfiles() []string {
+	var names []string
+	for _, p := range o.profiles.Profiles() {
+		if _, ok := p.Speed(o.hashName); ok {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// supportedHashes lists the hash algorithms the selected profile has a speed for,
+// or every algorithm when no profile has been chosen yet
+func (o *options) supportedHashes() []string {
+	p, ok := o.profiles.Find(o.system)
+	if !ok {
+		return hash.GetHashOptions()
+	}
+	var names []string
+	for _, name := range hash.GetHashOptions() {
+		if _, ok := p.Speed(name); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		spec string
+		want float64
+	}{
+		{"30d", 30 * 86400},
+		{"1.5d", 1.5 * 86400},
+		{"2w", 14 * 86400},
+		{"1y", 31557600},
+		{"12h", 12 * 3600},
+		{"90m", 5400},
+		{"1h30m", 5400},
+	}
+	for _, tt := range tests {
+		got, err := parseBudget(tt.spec)
+		if err != nil {
+			t.Errorf("parseBudget(%q): %v", tt.spec, err)
+		} else if got != tt.want {
+			t.Errorf("parseBudget(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"30", "d", "30x", "thirty days", "0d", "-5d", "0s", "-1h", "Infd", "NaNd"} {
+		if got, err := parseBudget(spec); err == nil {
+			t.Errorf("parseBudget(%q) = %v, want an error", spec, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package password
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// adjacencyGraph maps each key character to its neighbours. Every entry has
+// the same number of slots, one per direction, holding the unshifted and
+// shifted characters of the neighbouring key or "" when there is none.
+type adjacencyGraph struct {
+	name              string
+	neighbours        map[rune][]string
+	shifted           map[rune]bool // characters typed with shift held
+	startingPositions float64
+	averageDegree     float64
+}
+
+const qwertyLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+const azertyLayout = `
+   &1 é2 "3 '4 (5 -6 è7 _8 ç9 à0 )° =+
+    aA zZ eE rR tT yY uU iI oO pP ^¨ $£
+     qQ sS dD fF gG hH jJ kK lL mM ù% *µ
+   <> wW xX cC vV bB nN ,? ;. :/ !§
+`
+
+const qwertzLayout = `
+^° 1! 2" 3§ 4$ 5% 6& 7/ 8( 9) 0= ß? ´` + "`" + `
+    qQ wW eE rR tT zZ uU iI oO pP üÜ +*
+     aA sS dD fF gG hH jJ kK lL öÖ äÄ #'
+   <> yY xX cC vV bB nN mM ,; .: -_
+`
+
+const dvorakLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}
+    '" ,< .> pP yY fF gG cC rR lL /? =+ \|
+     aA oO eE uU iI dD hH tT nN sS -_
+      ;: qQ jJ kK xX bB mM wW vV zZ
+`
+
+const keypadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+
+// keyboardGraphs are the layouts searched for keyboard walks
+var keyboardGraphs = []*adjacencyGraph{
+	buildAdjacencyGraph("QWERTY", qwertyLayout, true),
+	buildAdjacencyGraph("AZERTY", azertyLayout, true),
+	buildAdjacencyGraph("QWERTZ", qwertzLayout, true),
+	buildAdjacencyGraph("Dvorak", dvorakLayout, true),
+	buildAdjacencyGraph("keypad", keypadLayout, false),
+}
+
+// buildAdjacencyGraph turns a drawing of a keyboard into an adjacency graph.
+// Keys on slanted keyboards have six neighbours, each row being shifted half
+// a key to the right of the one above; keys on aligned keypads have eight.
+func buildAdjacencyGraph(name, layout string, slanted bool) *adjacencyGraph {
+	type coord struct{ x, y int }
+
+	positions := map[coord]string{}
+	tokens := strings.Fields(layout)
+	xUnit := utf8.RuneCountInString(tokens[0]) + 1
+
+	for y, line := range strings.Split(layout, "\n") {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		for _, token := range strings.Fields(line) {
+			// Layouts contain multi-byte characters, so measure offsets in runes
+			offset := utf8.RuneCountInString(line[:strings.Index(line, token)])
+			x := (offset - slant) / xUnit
+			positions[coord{x, y}] = token
+		}
+	}
+
+	adjacent := func(x, y int) []coord {
+		if slanted {
+			return []coord{{x - 1, y}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y + 1}}
+		}
+		return []coord{{x - 1, y}, {x - 1, y - 1}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x - 1, y + 1}}
+	}
+
+	graph := &adjacencyGraph{name: name, neighbours: map[rune][]string{}, shifted: map[rune]bool{}}
+	degrees := 0
+	for pos, chars := range positions {
+		// The second character of a key is its shifted form
+		for i, char := range []rune(chars) {
+			if i > 0 {
+				graph.shifted[char] = true
+			}
+		}
+
+		var neighbours []string
+		for _, c := range adjacent(pos.x, pos.y) {
+			neighbours = append(neighbours, positions[c])
+			if positions[c] != "" {
+				degrees++
+			}
+		}
+		for _, char := range chars {
+			graph.neighbours[char] = neighbours
+		}
+	}
+
+	graph.startingPositions = float64(len(graph.neighbours))
+	graph.averageDegree = float64(degrees) / float64(len(positions))
+
+	return graph
+}
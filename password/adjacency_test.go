@@ -0,0 +1,136 @@
+package password
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// walk is the part of a spatial match the tests compare
+type walk struct {
+	graph   string
+	token   string
+	turns   int
+	shifted int
+}
+
+func TestSpatialMatch(t *testing.T) {
+	tests := []struct {
+		password string
+		want     []walk
+	}{
+		{"qwerty123", []walk{
+			{"QWERTY", "qwerty", 1, 0},
+			{"QWERTY", "123", 1, 0},
+			{"AZERTY", "erty", 1, 0},
+			// Digits are shifted on AZERTY
+			{"AZERTY", "123", 1, 3},
+			{"QWERTZ", "qwert", 1, 0},
+			{"QWERTZ", "123", 1, 0},
+			{"Dvorak", "123", 1, 0},
+			{"keypad", "123", 1, 0},
+		}},
+		// Two columns down the left of the keyboard
+		{"1qaz2wsx", []walk{
+			{"QWERTY", "1qaz", 1, 0},
+			{"QWERTY", "2wsx", 1, 0},
+			{"AZERTY", "qaz2", 3, 1},
+			{"AZERTY", "wsx", 2, 0},
+			{"QWERTZ", "1qa", 1, 0},
+			{"QWERTZ", "2wsx", 1, 0},
+		}},
+		{"azerty", []walk{
+			{"QWERTY", "erty", 1, 0},
+			{"AZERTY", "azerty", 1, 0},
+			{"QWERTZ", "ert", 1, 0},
+		}},
+		{"yxcvbn", []walk{
+			{"QWERTY", "xcvbn", 1, 0},
+			{"AZERTY", "xcvbn", 1, 0},
+			{"QWERTZ", "yxcvbn", 1, 0},
+		}},
+		{"aoeuidhtns", []walk{{"Dvorak", "aoeuidhtns", 1, 0}}},
+		{"QWErty", []walk{
+			{"QWERTY", "QWErty", 1, 3},
+			{"AZERTY", "Erty", 1, 1},
+			{"QWERTZ", "QWErt", 1, 3},
+		}},
+		{"!@#$%^", []walk{
+			{"QWERTY", "!@#$%^", 1, 6},
+			{"AZERTY", "$%^", 2, 1},
+			{"Dvorak", "!@#$%^", 1, 6},
+		}},
+		// Up the middle column, then turning left and down
+		{"852147", []walk{{"keypad", "852147", 3, 0}}},
+		{"xyz", nil},
+	}
+	for _, tt := range tests {
+		var got []walk
+		for _, m := range spatialMatch([]rune(tt.password)) {
+			if m.Token != string([]rune(tt.password)[m.I:m.J+1]) {
+				t.Errorf("%q: token %q does not match runes %d to %d", tt.password, m.Token, m.I, m.J)
+			}
+			got = append(got, walk{m.Graph, m.Token, m.Turns, m.ShiftedCount})
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%q: walks %v, want %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+// TestSpatialGuesses checks the guess counts against zxcvbn's, which uses
+// the same 94 starting positions and average degree of 4.5957 on QWERTY and
+// 15 and 5.0667 on the keypad
+func TestSpatialGuesses(t *testing.T) {
+	tests := []struct {
+		match Match
+		want  float64
+	}{
+		// 5 steps, each from any of 94 keys to one of 4.5957 neighbours
+		{Match{Graph: "QWERTY", Token: "qwerty", Turns: 1}, 2160},
+		// Any 3 of the 6 keys may be shifted: 6 + 15 + 20 = 41 ways
+		{Match{Graph: "QWERTY", Token: "QWErty", Turns: 1, ShiftedCount: 3}, 2160 * 41},
+		// Every key shifted only doubles the count
+		{Match{Graph: "QWERTY", Token: "!@#$%^", Turns: 1, ShiftedCount: 6}, 2160 * 2},
+		{Match{Graph: "keypad", Token: "963.", Turns: 1}, 228},
+		// A second turn adds the 2 walks of 3 keys that turn at either key
+		{Match{Graph: "QWERTY", Token: "qwe", Turns: 2}, 2*94*4.595744680851064 + 2*94*math.Pow(4.595744680851064, 2)},
+	}
+	for _, tt := range tests {
+		if got := spatialGuesses(&tt.match); math.Abs(got-tt.want) > 1e-6*tt.want {
+			t.Errorf("%s %q with %d turns: guesses = %v, want %v", tt.match.Graph, tt.match.Token, tt.match.Turns, got, tt.want)
+		}
+	}
+}
+
+// TestKeyboardWalksReduceGuesses checks that walks not in any dictionary are
+// estimated far below a brute force of their character classes
+func TestKeyboardWalksReduceGuesses(t *testing.T) {
+	tests := []struct {
+		password string
+		graph    string
+		want     int64
+	}{
+		{"aoeuidhtns", "Dvorak", 3889},
+		{"1qaz", "QWERTY", 1297},
+		{"963.", "keypad", 229},
+		{"!@#$%^", "QWERTY", 4321},
+	}
+	for _, tt := range tests {
+		estimate := EstimateGuesses(tt.password)
+		if len(estimate.Sequence) != 1 || estimate.Sequence[0].Pattern != "spatial" || estimate.Sequence[0].Graph != tt.graph {
+			t.Errorf("%q: sequence %v, want a single %s walk", tt.password, estimate.Sequence, tt.graph)
+			continue
+		}
+		if estimate.Guesses.Int64() != tt.want {
+			t.Errorf("%q: Guesses = %v, want %d", tt.password, estimate.Guesses, tt.want)
+		}
+
+		a := Analyze(tt.password)
+		bruteforce := CalculateCombinations(a.Length, a.CharsetSize)
+		if estimate.Guesses.Cmp(new(big.Int).Div(bruteforce, big.NewInt(100))) >= 0 {
+			t.Errorf("%q: Guesses = %v, want well below the %v combinations", tt.password, estimate.Guesses, bruteforce)
+		}
+	}
+}
@@ -1,25 +1,365 @@
 package password
 
-// AnalyzePassword checks password characteristics
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Analysis describes the characters that make up a password
+type Analysis struct {
+	Length int // user-perceived characters (grapheme clusters)
+	Runes  int // Unicode code points
+	Bytes  int // UTF-8 encoded size
+
+	HasLower    bool // lowercase letters in any script
+	HasUpper    bool // uppercase or titlecase letters in any script
+	HasCaseless bool // letters of scripts without case, such as Arabic or Han
+	HasDigit    bool // decimal digits in any script
+	HasSpecial  bool // punctuation, symbols, spaces and other characters
+	HasEmoji    bool
+
+	Categories map[string]int // grapheme count per character category
+	Scripts    map[string]int // grapheme count per script
+	Charsets   []Charset      // character sets an attacker would have to cover
+
+	CharsetSize int
+}
+
+// Charset is one group of characters counted towards the brute-force character set size
+type Charset struct {
+	Name string
+	Size int
+}
+
+// Character categories reported in Analysis.Categories
+const (
+	CategoryLowercase   = "Lowercase letter"
+	CategoryUppercase   = "Uppercase letter"
+	CategoryCaseless    = "Letter without case"
+	CategoryDigit       = "Digit"
+	CategoryNumber      = "Other number"
+	CategoryPunctuation = "Punctuation"
+	CategorySymbol      = "Symbol"
+	CategoryEmoji       = "Emoji"
+	CategorySpace       = "Space"
+	CategoryMark        = "Combining mark"
+	CategoryOther       = "Other"
+)
+
+// Approximate alphabet sizes of scripts; cased scripts give the size of one case
+var scriptAlphabetSizes = map[string]int{
+	"Arabic":     36,
+	"Armenian":   38,
+	"Bengali":    50,
+	"Cyrillic":   33,
+	"Devanagari": 48,
+	"Ethiopic":   230,
+	"Georgian":   33,
+	"Greek":      24,
+	"Gujarati":   47,
+	"Gurmukhi":   41,
+	"Han":        3500, // commonly used Chinese characters
+	"Hangul":     2350, // syllables in the KS X 1001 standard
+	"Hebrew":     27,
+	"Hiragana":   46,
+	"Kannada":    49,
+	"Katakana":   46,
+	"Khmer":      74,
+	"Lao":        54,
+	"Malayalam":  51,
+	"Myanmar":    45,
+	"Sinhala":    60,
+	"Tamil":      31,
+	"Telugu":     56,
+	"Thai":       44,
+}
+
+// Sizes used for the remaining groups of characters
+const (
+	asciiLetterCharsetSize    = 26
+	latinExtendedCharsetSize  = 30 // accented letters of one case used across European and African languages
+	defaultScriptCharsetSize  = 50
+	digitCharsetSize          = 10
+	asciiSpecialCharsetSize   = 33 // printable ASCII punctuation, symbols and space
+	unicodeSpecialCharsetSize = 100
+	markCharsetSize           = 10 // combining marks commonly used with one script
+	emojiCharsetSize          = 1400
+)
+
+// AnalyzePassword checks password characteristics. Letters without case are
+// counted as lowercase and emoji as special characters.
 func AnalyzePassword(password string) (int, bool, bool, bool, bool) {
-	length := len(password)
-	hasLower := false
-	hasUpper := false
-	hasDigit := false
-	hasSpecial := false
-
-	for _, char := range password {
-		switch {
-		case 'a' <= char && char <= 'z':
-			hasLower = true
-		case 'A' <= char && char <= 'Z':
-			hasUpper = true
-		case '0' <= char && char <= '9':
-			hasDigit = true
+	a := Analyze(password)
+	hasLower, hasUpper, hasDigit, hasSpecial := a.CharacterClasses()
+	return a.Length, hasLower, hasUpper, hasDigit, hasSpecial
+}
+
+// CharacterClasses folds the analysis into the four classes used by GetStrength
+func (a Analysis) CharacterClasses() (hasLower, hasUpper, hasDigit, hasSpecial bool) {
+	return a.HasLower || a.HasCaseless, a.HasUpper, a.HasDigit, a.HasSpecial || a.HasEmoji
+}
+
+// Analyze classifies every character of the password by Unicode category and
+// script and sizes the character set for the scripts that actually appear
+func Analyze(password string) Analysis {
+	a := Analysis{
+		Runes:      utf8.RuneCountInString(password),
+		Bytes:      len(password),
+		Categories: map[string]int{},
+		Scripts:    map[string]int{},
+	}
+
+	// Which character groups appear, keyed by script and group
+	type group struct{ script, kind string }
+	seen := map[group]bool{}
+
+	// Classify the composed form, so "é" counts as the letter it is whether
+	// it was typed as one code point or as "e" and a combining accent. Runes
+	// and Bytes still describe the password as given, which is what is hashed.
+	for _, cluster := range graphemeClusters(norm.NFC.String(password)) {
+		a.Length++
+
+		base := cluster[0]
+		script := scriptOf(base)
+		category := categoryOf(base, cluster)
+		a.Categories[category]++
+		a.Scripts[script]++
+
+		switch category {
+		case CategoryLowercase:
+			a.HasLower = true
+		case CategoryUppercase:
+			a.HasUpper = true
+		case CategoryCaseless:
+			a.HasCaseless = true
+		case CategoryDigit:
+			a.HasDigit = true
+		case CategoryEmoji:
+			a.HasEmoji = true
 		default:
-			hasSpecial = true
+			a.HasSpecial = true
+		}
+
+		kind := category
+		if script == "Latin" && base > unicode.MaxASCII && (category == CategoryLowercase || category == CategoryUppercase) {
+			kind = "extended " + category
+		}
+		if (category == CategoryPunctuation || category == CategorySymbol || category == CategorySpace) && base <= unicode.MaxASCII {
+			kind = "ASCII special"
+		}
+		seen[group{script, kind}] = true
+
+		// Combining marks add their own variations on top of the base letter
+		for _, r := range cluster[1:] {
+			if unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me) {
+				seen[group{scriptOf(base), CategoryMark}] = true
+			}
 		}
 	}
 
-	return length, hasLower, hasUpper, hasDigit, hasSpecial
-} 
\ No newline at end of file
+	var groups []group
+	for g := range seen {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].script != groups[j].script {
+			return groups[i].script < groups[j].script
+		}
+		return groups[i].kind < groups[j].kind
+	})
+
+	for _, g := range groups {
+		name, size := charsetFor(g.script, g.kind)
+		a.Charsets = append(a.Charsets, Charset{Name: name, Size: size})
+		a.CharsetSize += size
+	}
+
+	// Ensure at least 1 character in charset
+	if a.CharsetSize == 0 {
+		a.CharsetSize = 1
+	}
+
+	return a
+}
+
+// charsetFor names and sizes the character set for one kind of character in one script
+func charsetFor(script, kind string) (string, int) {
+	switch kind {
+	case "ASCII special":
+		return "ASCII special characters", asciiSpecialCharsetSize
+	case CategoryPunctuation, CategorySymbol, CategorySpace, CategoryOther:
+		return "Unicode " + kind, unicodeSpecialCharsetSize
+	case CategoryEmoji:
+		return "Emoji", emojiCharsetSize
+	case CategoryDigit:
+		if script == "Common" {
+			return "Digits (0-9)", digitCharsetSize
+		}
+		return script + " digits", digitCharsetSize
+	case CategoryNumber:
+		return script + " numbers", digitCharsetSize
+	case CategoryMark:
+		return script + " combining marks", markCharsetSize
+	case "extended " + CategoryLowercase:
+		return "Latin accented lowercase letters", latinExtendedCharsetSize
+	case "extended " + CategoryUppercase:
+		return "Latin accented uppercase letters", latinExtendedCharsetSize
+	}
+
+	size := defaultScriptCharsetSize
+	if script == "Latin" {
+		size = asciiLetterCharsetSize
+	} else if known, ok := scriptAlphabetSizes[script]; ok {
+		size = known
+	}
+
+	switch kind {
+	case CategoryLowercase:
+		return script + " lowercase letters", size
+	case CategoryUppercase:
+		return script + " uppercase letters", size
+	default:
+		return script + " letters", size
+	}
+}
+
+// categoryOf classifies a grapheme cluster by its first rune
+func categoryOf(base rune, cluster []rune) string {
+	switch {
+	case isEmoji(base) || (len(cluster) > 1 && isRegionalIndicator(base)):
+		return CategoryEmoji
+	case unicode.IsLower(base):
+		return CategoryLowercase
+	case unicode.IsUpper(base) || unicode.IsTitle(base):
+		return CategoryUppercase
+	case unicode.IsLetter(base):
+		return CategoryCaseless
+	case unicode.Is(unicode.Nd, base):
+		return CategoryDigit
+	case unicode.IsNumber(base):
+		return CategoryNumber
+	case unicode.IsPunct(base):
+		return CategoryPunctuation
+	case unicode.IsSpace(base):
+		return CategorySpace
+	case unicode.IsSymbol(base):
+		return CategorySymbol
+	case unicode.IsMark(base):
+		return CategoryMark
+	default:
+		return CategoryOther
+	}
+}
+
+// scriptOf returns the name of the Unicode script a rune belongs to
+func scriptOf(r rune) string {
+	if isEmoji(r) {
+		return "Emoji"
+	}
+	for _, name := range scriptNames {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
+
+// scriptNames lists the Unicode scripts, most common first so lookups stay cheap
+var scriptNames = func() []string {
+	first := []string{"Latin", "Common", "Inherited", "Arabic", "Cyrillic", "Greek", "Han", "Hiragana", "Katakana", "Hangul", "Hebrew", "Devanagari"}
+	names := append([]string(nil), first...)
+
+	var rest []string
+	for name := range unicode.Scripts {
+		known := false
+		for _, f := range first {
+			if f == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(names, rest...)
+}()
+
+// graphemeClusters splits a string into user-perceived characters. It
+// implements the parts of Unicode's grapheme cluster rules that matter for
+// passwords: CR LF, combining marks, variation selectors, emoji modifiers,
+// zero-width-joiner emoji sequences, flag pairs and Hangul jamo.
+func graphemeClusters(s string) [][]rune {
+	var clusters [][]rune
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if len(clusters) > 0 {
+			last := clusters[len(clusters)-1]
+			prev := last[len(last)-1]
+			if joinsPrevious(prev, r, last) {
+				clusters[len(clusters)-1] = append(last, r)
+				continue
+			}
+		}
+		clusters = append(clusters, []rune{r})
+	}
+
+	return clusters
+}
+
+// joinsPrevious reports whether r continues the cluster ending in prev
+func joinsPrevious(prev, r rune, cluster []rune) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return true
+	case unicode.In(r, unicode.Mn, unicode.Mc, unicode.Me):
+		return true
+	case r == 0x200D: // zero-width joiner
+		return true
+	case 0xFE00 <= r && r <= 0xFE0F: // variation selectors
+		return true
+	case 0x1F3FB <= r && r <= 0x1F3FF: // emoji skin tone modifiers
+		return true
+	case 0xE0020 <= r && r <= 0xE007F: // emoji tag sequences
+		return true
+	case prev == 0x200D && isEmoji(r):
+		return true
+	case isRegionalIndicator(prev) && isRegionalIndicator(r):
+		// Flags are pairs of regional indicators
+		count := 0
+		for _, c := range cluster {
+			if isRegionalIndicator(c) {
+				count++
+			}
+		}
+		return count%2 == 1
+	case isHangulJamo(prev) && 0x1160 <= r && r <= 0x11FF: // vowel and trailing jamo
+		return true
+	}
+	return false
+}
+
+// isEmoji approximates Unicode's Extended_Pictographic property
+func isEmoji(r rune) bool {
+	return (0x1F000 <= r && r <= 0x1FAFF) ||
+		(0x2600 <= r && r <= 0x27BF) ||
+		(0x2B00 <= r && r <= 0x2BFF) ||
+		(0x2300 <= r && r <= 0x23FF && unicode.IsSymbol(r))
+}
+
+// isRegionalIndicator reports whether r is one of the letters used to build flag emoji
+func isRegionalIndicator(r rune) bool {
+	return 0x1F1E6 <= r && r <= 0x1F1FF
+}
+
+// isHangulJamo reports whether r is a conjoining Hangul jamo
+func isHangulJamo(r rune) bool {
+	return 0x1100 <= r && r <= 0x11FF
+}
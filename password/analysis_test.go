@@ -0,0 +1,22 @@
+package password
+
+import "testing"
+
+// TestAnalyzeNormalizes checks that a password typed with combining accents
+// is classified like the same password with precomposed letters
+func TestAnalyzeNormalizes(t *testing.T) {
+	tests := []struct{ composed, decomposed string }{
+		{"caf\u00e9", "cafe\u0301"},
+		{"\u00c9l\u00e8ve2024", "E\u0301le\u0300ve2024"},
+	}
+	for _, tt := range tests {
+		want, got := Analyze(tt.composed), Analyze(tt.decomposed)
+		if got.CharsetSize != want.CharsetSize || got.Length != want.Length {
+			t.Errorf("%+q: charset %d and length %d, want %d and %d as for %+q",
+				tt.decomposed, got.CharsetSize, got.Length, want.CharsetSize, want.Length, tt.composed)
+		}
+		if got.Runes == want.Runes {
+			t.Errorf("%+q: Runes = %d, want the code points as given", tt.decomposed, got.Runes)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package password
+
+import (
+	"embed"
+	"strings"
+	"sync"
+)
+
+// The frequency lists are ordered from most to least common and are derived
+// from the lists published with Dropbox's zxcvbn (MIT licence, see
+// dictionaries/LICENSE)
+//
+//go:embed dictionaries/*.txt
+var dictionaryFiles embed.FS
+
+// Names of the built-in ranked dictionaries
+var dictionaryNames = []string{"passwords", "english", "female_names", "male_names", "surnames"}
+
+var (
+	rankedDictionaries     map[string]map[string]int
+	rankedDictionariesOnce sync.Once
+)
+
+// dictionaries returns the built-in dictionaries, mapping each word to its frequency rank
+func dictionaries() map[string]map[string]int {
+	rankedDictionariesOnce.Do(func() {
+		rankedDictionaries = make(map[string]map[string]int, len(dictionaryNames))
+		for _, name := range dictionaryNames {
+			data, err := dictionaryFiles.ReadFile("dictionaries/" + name + ".txt")
+			if err != nil {
+				// The files are embedded at build time, so this cannot happen
+				panic(err)
+			}
+			rankedDictionaries[name] = buildRankedDictionary(strings.Split(string(data), "\n"))
+		}
+	})
+	return rankedDictionaries
+}
+
+// buildRankedDictionary maps each word to its 1-based position in the list
+func buildRankedDictionary(words []string) map[string]int {
+	ranked := make(map[string]int, len(words))
+	rank := 0
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		rank++
+		if _, exists := ranked[word]; !exists {
+			ranked[word] = rank
+		}
+	}
+	return ranked
+}
@@ -0,0 +1,60 @@
+package password
+
+import (
+	"math/big"
+)
+
+// GuessDistribution describes where the password lies in the attacker's
+// guessing order. A password found by rank, such as a dictionary word or a
+// list entry, is reached at a known guess; a password with a brute-forced
+// part is equally likely to be anywhere in the space the attacker searches,
+// so on average it falls halfway through.
+type GuessDistribution struct {
+	// Guesses is the guess that reaches the password when Exact, and the size
+	// of the space searched otherwise
+	Guesses *big.Int
+	Exact   bool
+}
+
+// NewGuessDistribution returns the distribution of a password needing the
+// given guesses, which are exact unless some part of it is brute-forced
+func NewGuessDistribution(guesses *big.Int, bruteforced bool) GuessDistribution {
+	return GuessDistribution{Guesses: guesses, Exact: !bruteforced}
+}
+
+// Expected returns the average number of guesses the attacker needs
+func (d GuessDistribution) Expected() *big.Float {
+	return d.Percentile(50)
+}
+
+// Percentile returns the guesses after which the attacker has cracked the
+// password with the given probability, in percent
+func (d GuessDistribution) Percentile(percent float64) *big.Float {
+	guesses := new(big.Float).SetInt(d.Guesses)
+	if d.Exact {
+		return guesses
+	}
+	return guesses.Mul(guesses, big.NewFloat(percent/100))
+}
+
+// Probability returns the chance, from 0 to 1, that the password falls
+// within the given number of guesses
+func (d GuessDistribution) Probability(tried *big.Float) float64 {
+	guesses := new(big.Float).SetInt(d.Guesses)
+	if tried.Cmp(guesses) >= 0 {
+		return 1
+	}
+	if d.Exact || guesses.Sign() == 0 {
+		return 0
+	}
+	p, _ := new(big.Float).Quo(tried, guesses).Float64()
+	return p
+}
+
+// SecondsFor returns the time needed for a number of guesses at the given speed
+func SecondsFor(guesses *big.Float, hashesPerSecond float64) *big.Float {
+	if hashesPerSecond <= 0 {
+		hashesPerSecond = 1
+	}
+	return new(big.Float).Quo(guesses, big.NewFloat(hashesPerSecond))
+}
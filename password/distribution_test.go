@@ -0,0 +1,66 @@
+package password
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestGuessDistribution(t *testing.T) {
+	ranked := NewGuessDistribution(big.NewInt(1000), false)
+	uniform := NewGuessDistribution(big.NewInt(1000), true)
+
+	tests := []struct {
+		name string
+		dist GuessDistribution
+		// Guesses for a 10%, 50% and 90% chance
+		percentiles [3]float64
+		// Chance after 0, 100, 999, 1000 and 5000 guesses
+		probabilities [5]float64
+	}{
+		// A ranked password is found at its rank, never before
+		{"ranked", ranked, [3]float64{1000, 1000, 1000}, [5]float64{0, 0, 0, 1, 1}},
+		// A brute-forced one is as likely to be anywhere in the space
+		{"uniform", uniform, [3]float64{100, 500, 900}, [5]float64{0, 0.1, 0.999, 1, 1}},
+		{"empty", NewGuessDistribution(big.NewInt(0), true), [3]float64{0, 0, 0}, [5]float64{1, 1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		for i, percent := range []float64{10, 50, 90} {
+			if got, _ := tt.dist.Percentile(percent).Float64(); got != tt.percentiles[i] {
+				t.Errorf("%s: Percentile(%v) = %v, want %v", tt.name, percent, got, tt.percentiles[i])
+			}
+		}
+		if got, _ := tt.dist.Expected().Float64(); got != tt.percentiles[1] {
+			t.Errorf("%s: Expected() = %v, want the median %v", tt.name, got, tt.percentiles[1])
+		}
+		for i, tried := range []float64{0, 100, 999, 1000, 5000} {
+			if got := tt.dist.Probability(big.NewFloat(tried)); got != tt.probabilities[i] {
+				t.Errorf("%s: Probability(%v) = %v, want %v", tt.name, tried, got, tt.probabilities[i])
+			}
+		}
+	}
+
+	// For the same number of guesses, the uniform password is expected
+	// sooner but the ranked one is certain sooner
+	if uniform.Expected().Cmp(ranked.Expected()) >= 0 {
+		t.Errorf("uniform expected %v, want fewer than ranked %v", uniform.Expected(), ranked.Expected())
+	}
+	if uniform.Percentile(100).Cmp(ranked.Percentile(100)) != 0 {
+		t.Errorf("uniform and ranked passwords are not certain at the same guess")
+	}
+}
+
+func TestSecondsFor(t *testing.T) {
+	tests := []struct {
+		guesses, speed, want float64
+	}{
+		{1e12, 1e9, 1000},
+		{0, 1e9, 0},
+		// A speed of zero is treated as one guess per second
+		{500, 0, 500},
+	}
+	for _, tt := range tests {
+		if got, _ := SecondsFor(big.NewFloat(tt.guesses), tt.speed).Float64(); got != tt.want {
+			t.Errorf("SecondsFor(%v, %v) = %v, want %v", tt.guesses, tt.speed, got, tt.want)
+		}
+	}
+}
@@ -89,19 +89,44 @@ func FormatTime(seconds *big.Float) (string, string, string) {
 
 // EstimateCrackTime estimates the time required to crack the password
 func EstimateCrackTime(combinations *big.Int, hashesPerSecond int64) (string, string, string) {
+	return FormatTime(CrackSeconds(combinations, float64(hashesPerSecond)))
+}
+
+// CrackSeconds returns the number of seconds needed to try every combination
+func CrackSeconds(combinations *big.Int, hashesPerSecond float64) *big.Float {
 	// Avoid division by zero
 	if hashesPerSecond <= 0 {
 		hashesPerSecond = 1
 	}
 	
 	// Calculate seconds required = combinations / hashes per second
-	hashesPerSecondBig := new(big.Float).SetInt64(hashesPerSecond)
+	hashesPerSecondBig := big.NewFloat(hashesPerSecond)
 	combinationsBig := new(big.Float).SetInt(combinations)
 	
 	// seconds = combinations / hashesPerSecond
-	seconds := new(big.Float).Quo(combinationsBig, hashesPerSecondBig)
-	
-	return FormatTime(seconds)
+	return new(big.Float).Quo(combinationsBig, hashesPerSecondBig)
+}
+
+// InterpretCrackTime turns a cracking time into a human-readable security assessment
+func InterpretCrackTime(seconds *big.Float) string {
+	switch {
+	case seconds.Cmp(big.NewFloat(60)) < 0:
+		return "Extremely Weak: This password would be cracked instantly!"
+	case seconds.Cmp(big.NewFloat(3600)) < 0: // < 1 hour
+		return "Very Weak: This password would be cracked in minutes!"
+	case seconds.Cmp(big.NewFloat(86400)) < 0: // < 1 day
+		return "Weak: This password would be cracked in hours."
+	case seconds.Cmp(big.NewFloat(604800)) < 0: // < 1 week
+		return "Moderate: This password would take a few days to crack."
+	case seconds.Cmp(big.NewFloat(2592000)) < 0: // < 1 month
+		return "Good: This password would take weeks to crack."
+	case seconds.Cmp(big.NewFloat(31557600)) < 0: // < 1 year
+		return "Strong: This password would take months to crack."
+	case seconds.Cmp(big.NewFloat(315576000)) < 0: // < 10 years
+		return "Very Strong: This password would take years to crack."
+	default:
+		return "Excellent: This password would take decades or more to crack."
+	}
 }
 
 // Helper function to format big.Float values with reasonable precision
@@ -0,0 +1,152 @@
+package password
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// StrongCrackSeconds is the crack time InterpretCrackTime rates as its top
+// band, used as the goal of length advice: ten years
+const StrongCrackSeconds = 315576000
+
+// maxExtraLength bounds the length advice for passwords an attacker reaches
+// almost at once with any speed
+const maxExtraLength = 64
+
+// minWhatIfSeconds is the shortest crack time a what-if comparison grows the
+// password to, as reports show anything shorter as "< 0.01 seconds"
+const minWhatIfSeconds = 0.01
+
+// PatternFeedback explains, for each guessable pattern in the cheapest match
+// sequence and each keyboard walk, why it makes the password easier to guess
+func PatternFeedback(password string, estimate GuessEstimate) []string {
+	length := utf8.RuneCountInString(password)
+	var feedback []string
+	seen := map[string]bool{}
+	add := func(message string) {
+		if !seen[message] {
+			seen[message] = true
+			feedback = append(feedback, message)
+		}
+	}
+
+	matches := append(append([]*Match(nil), estimate.Sequence...), unexplainedWalks(estimate)...)
+	for _, m := range matches {
+		switch m.Pattern {
+		case "dictionary":
+			add(describeWord(m))
+			if m.Reversed {
+				add(fmt.Sprintf("%q is a reversed word, which attackers try as well", m.Token))
+			}
+			if m.L33t {
+				add(fmt.Sprintf("substitutions such as in %q are predictable and add little", m.Token))
+			}
+			if startsUpperOnly(m.Token) {
+				add(fmt.Sprintf("capitalising only the first letter of %q adds little", m.Token))
+			}
+		case "spatial":
+			add(fmt.Sprintf("contains the keyboard pattern %q", m.Token))
+		case "repeat":
+			add(fmt.Sprintf("repeats %q %d times; repeats are barely harder to guess than one copy", m.BaseToken, m.RepeatCount))
+		case "sequence":
+			add(fmt.Sprintf("contains the sequence %q", m.Token))
+		case "regex":
+			if m.RegexName == "recent_year" {
+				add(yearFeedback(m, length))
+			}
+		case "date":
+			add(fmt.Sprintf("contains the date %q; dates are easy to guess, especially personal ones", m.Token))
+		}
+	}
+	return feedback
+}
+
+// minReportedWalk is the shortest keyboard walk worth pointing out on its own
+const minReportedWalk = 4
+
+// unexplainedWalks returns the keyboard walks the match sequence does not
+// already cover: those of at least minReportedWalk keys that lie inside no
+// other pattern of the sequence and no longer walk
+func unexplainedWalks(estimate GuessEstimate) []*Match {
+	covers := func(outer, inner *Match) bool {
+		return outer != inner && outer.I <= inner.I && inner.J <= outer.J
+	}
+
+	var walks []*Match
+	for _, w := range estimate.KeyboardWalks {
+		if w.J-w.I+1 < minReportedWalk {
+			continue
+		}
+		covered := false
+		for _, m := range estimate.Sequence {
+			covered = covered || (m.Pattern != "bruteforce" && covers(m, w))
+		}
+		for _, other := range estimate.KeyboardWalks {
+			covered = covered || (covers(other, w) && other.J-other.I > w.J-w.I)
+		}
+		if !covered {
+			walks = append(walks, w)
+		}
+	}
+	return walks
+}
+
+// describeWord names the kind of dictionary word a match found
+func describeWord(m *Match) string {
+	switch m.Dictionary {
+	case "passwords":
+		return fmt.Sprintf("contains the common password %q", m.MatchedWord)
+	case "female_names", "male_names", "surnames":
+		return fmt.Sprintf("contains the name %q", m.MatchedWord)
+	default:
+		return fmt.Sprintf("contains the common word %q", m.MatchedWord)
+	}
+}
+
+// yearFeedback says where a year appears, since a trailing year is the most common habit
+func yearFeedback(m *Match, length int) string {
+	switch {
+	case m.J == length-1:
+		return fmt.Sprintf("ends with the year %q", m.Token)
+	case m.I == 0:
+		return fmt.Sprintf("starts with the year %q", m.Token)
+	default:
+		return fmt.Sprintf("contains the year %q", m.Token)
+	}
+}
+
+// startsUpperOnly reports whether a word is capitalised and otherwise lowercase
+func startsUpperOnly(token string) bool {
+	first, size := utf8.DecodeRuneInString(token)
+	rest := token[size:]
+	return unicode.IsUpper(first) && rest != "" && strings.ToLower(rest) == rest
+}
+
+// GrowGuesses returns the guesses needed once extra random characters from
+// a set of charsetSize characters are added to a password
+func GrowGuesses(guesses *big.Int, charsetSize, extra int) *big.Int {
+	factor := new(big.Int).Exp(big.NewInt(int64(max(charsetSize, bruteforceCardinality))), big.NewInt(int64(extra)), nil)
+	return factor.Mul(factor, guesses)
+}
+
+// ExtraLengthFor returns how many random characters from a set of
+// charsetSize characters must be added before cracking takes targetSeconds
+// at the given speed, or zero when it already does
+func ExtraLengthFor(guesses *big.Int, charsetSize int, hashesPerSecond, targetSeconds float64) int {
+	target := big.NewFloat(targetSeconds)
+	for extra := 0; extra <= maxExtraLength; extra++ {
+		if CrackSeconds(GrowGuesses(guesses, charsetSize, extra), hashesPerSecond).Cmp(target) >= 0 {
+			return extra
+		}
+	}
+	return maxExtraLength
+}
+
+// WhatIfLength returns how many random characters, at least minExtra, to add
+// to a password so that its crack time at the given speed visibly changes
+func WhatIfLength(guesses *big.Int, charsetSize, minExtra int, hashesPerSecond float64) int {
+	return max(minExtra, ExtraLengthFor(guesses, charsetSize, hashesPerSecond, minWhatIfSeconds))
+}
@@ -0,0 +1,73 @@
+package password
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestGrowGuesses(t *testing.T) {
+	tests := []struct {
+		guesses            int64
+		charsetSize, extra int
+		want               int64
+	}{
+		{100, 26, 2, 100 * 26 * 26},
+		{100, 26, 0, 100},
+		// Small character sets grow at least as fast as brute force of digits
+		{100, 4, 3, 100 * 1000},
+	}
+	for _, tt := range tests {
+		if got := GrowGuesses(big.NewInt(tt.guesses), tt.charsetSize, tt.extra); got.Int64() != tt.want {
+			t.Errorf("GrowGuesses(%d, %d, %d) = %v, want %d", tt.guesses, tt.charsetSize, tt.extra, got, tt.want)
+		}
+	}
+}
+
+func TestExtraLengthFor(t *testing.T) {
+	tests := []struct {
+		guesses     int64
+		charsetSize int
+		target      float64
+		want        int
+	}{
+		// One second at 1e6 guesses per second, so 100 seconds needs two digits
+		{1e6, 10, 100, 2},
+		{1e6, 10, 101, 3},
+		{1e6, 10, 1, 0},
+		{1e6, 62, 3600, 2},
+		// Out of reach within maxExtraLength characters
+		{1, 10, 1e80, maxExtraLength},
+	}
+	for _, tt := range tests {
+		if got := ExtraLengthFor(big.NewInt(tt.guesses), tt.charsetSize, 1e6, tt.target); got != tt.want {
+			t.Errorf("ExtraLengthFor(%d, %d, 1e6, %v) = %d, want %d", tt.guesses, tt.charsetSize, tt.target, got, tt.want)
+		}
+	}
+}
+
+// TestWhatIfLength checks that a password cracked at once is grown until its
+// crack time reaches a hundredth of a second, so the comparison does not read
+// "< 0.01 seconds instead of < 0.01 seconds"
+func TestWhatIfLength(t *testing.T) {
+	tests := []struct {
+		guesses     int64
+		charsetSize int
+		want        int
+	}{
+		// 1e-8 seconds at 5e8 guesses per second needs 26^5 times as many guesses
+		{5, 26, 5},
+		{20, 10, 6},
+		// Already visible, so two characters are enough
+		{5e8, 26, 2},
+		{5e6, 26, 2},
+	}
+	for _, tt := range tests {
+		got := WhatIfLength(big.NewInt(tt.guesses), tt.charsetSize, 2, 5e8)
+		if got != tt.want {
+			t.Errorf("WhatIfLength(%d, %d) = %d, want %d", tt.guesses, tt.charsetSize, got, tt.want)
+		}
+		if seconds := CrackSeconds(GrowGuesses(big.NewInt(tt.guesses), tt.charsetSize, got), 5e8); seconds.Cmp(big.NewFloat(minWhatIfSeconds)) < 0 {
+			t.Errorf("WhatIfLength(%d, %d): grown password cracked in %v seconds", tt.guesses, tt.charsetSize, seconds)
+		}
+	}
+}
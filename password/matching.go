@@ -0,0 +1,697 @@
+package password
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Match is a guessable pattern found in a substring of the password
+type Match struct {
+	Pattern string // dictionary, spatial, repeat, sequence, regex, date or bruteforce
+	I, J    int    // rune offsets of the first and last character of Token
+	Token   string
+	Guesses float64
+
+	// Dictionary matches
+	Dictionary  string
+	MatchedWord string
+	Rank        int
+	Reversed    bool
+	L33t        bool
+	Sub         map[rune]rune // l33t character to the letter it replaces
+
+	// Spatial matches
+	Graph        string
+	Turns        int
+	ShiftedCount int
+
+	// Repeat matches
+	BaseToken   string
+	BaseGuesses float64
+	RepeatCount int
+
+	// Sequence matches
+	SequenceName  string
+	SequenceSpace int
+	Ascending     bool
+
+	// Regex matches
+	RegexName string
+
+	// Date matches
+	Separator        string
+	Year, Month, Day int
+}
+
+// Describe returns a short human-readable explanation of the match
+func (m *Match) Describe() string {
+	switch m.Pattern {
+	case "dictionary":
+		desc := fmt.Sprintf("%s word %q (rank %d)", strings.ReplaceAll(m.Dictionary, "_", " "), m.MatchedWord, m.Rank)
+		if m.Reversed {
+			desc += ", reversed"
+		}
+		if m.L33t {
+			desc += ", l33t substitutions"
+		}
+		return desc
+	case "spatial":
+		return fmt.Sprintf("%s keyboard walk with %d turn(s)", m.Graph, m.Turns)
+	case "repeat":
+		return fmt.Sprintf("%q repeated %d times", m.BaseToken, m.RepeatCount)
+	case "sequence":
+		direction := "ascending"
+		if !m.Ascending {
+			direction = "descending"
+		}
+		return fmt.Sprintf("%s %s sequence", direction, m.SequenceName)
+	case "regex":
+		return strings.ReplaceAll(m.RegexName, "_", " ")
+	case "date":
+		return fmt.Sprintf("date %04d-%02d-%02d", m.Year, m.Month, m.Day)
+	default:
+		return "brute force"
+	}
+}
+
+// Limits of the pattern matchers
+const (
+	maxMatchLength   = 100 // longer passwords only have their first runes searched for patterns
+	maxSequenceDelta = 5
+	dateMinYear      = 1000
+	dateMaxYear      = 2050
+)
+
+// referenceYear is the year recent years and dates are measured against
+var referenceYear = time.Now().Year()
+
+// l33tTable lists the characters commonly substituted for each letter
+var l33tTable = map[rune][]rune{
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
+
+// dateSplits lists where a run of 4 to 8 digits may be cut into day, month and year
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+// omnimatch runs every matcher over the password
+func omnimatch(password []rune) []*Match {
+	if len(password) > maxMatchLength {
+		password = password[:maxMatchLength]
+	}
+
+	var matches []*Match
+	matches = append(matches, dictionaryMatch(password, dictionaries())...)
+	matches = append(matches, reverseDictionaryMatch(password)...)
+	matches = append(matches, l33tMatch(password)...)
+	matches = append(matches, spatialMatch(password)...)
+	matches = append(matches, repeatMatch(password)...)
+	matches = append(matches, sequenceMatch(password)...)
+	matches = append(matches, regexMatch(password)...)
+	matches = append(matches, dateMatch(password)...)
+
+	sortMatches(matches)
+	return matches
+}
+
+// sortMatches orders matches by position
+func sortMatches(matches []*Match) {
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].I != matches[b].I {
+			return matches[a].I < matches[b].I
+		}
+		return matches[a].J < matches[b].J
+	})
+}
+
+// dictionaryMatch finds every substring that is a word in one of the dictionaries
+func dictionaryMatch(password []rune, dicts map[string]map[string]int) []*Match {
+	var matches []*Match
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		// Lowercasing changed the rune count, so offsets would not line up
+		lower = password
+	}
+
+	for _, name := range sortedKeys(dicts) {
+		dict := dicts[name]
+		for i := range password {
+			for j := i; j < len(password); j++ {
+				word := string(lower[i : j+1])
+				if rank, ok := dict[word]; ok {
+					matches = append(matches, &Match{
+						Pattern:     "dictionary",
+						I:           i,
+						J:           j,
+						Token:       string(password[i : j+1]),
+						Dictionary:  name,
+						MatchedWord: word,
+						Rank:        rank,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// reverseDictionaryMatch finds dictionary words spelled backwards
+func reverseDictionaryMatch(password []rune) []*Match {
+	reversed := reverseRunes(password)
+	matches := dictionaryMatch(reversed, dictionaries())
+	for _, m := range matches {
+		m.Token = string(reverseRunes([]rune(m.Token)))
+		m.Reversed = true
+		m.I, m.J = len(password)-1-m.J, len(password)-1-m.I
+	}
+	return matches
+}
+
+// l33tMatch finds dictionary words written with l33t substitutions
+func l33tMatch(password []rune) []*Match {
+	var matches []*Match
+	seen := map[string]bool{}
+	for _, sub := range enumerateL33tSubs(relevantL33tTable(password)) {
+		if len(sub) == 0 {
+			continue
+		}
+
+		subbed := make([]rune, len(password))
+		for i, r := range password {
+			if letter, ok := sub[r]; ok {
+				r = letter
+			}
+			subbed[i] = r
+		}
+
+		for _, m := range dictionaryMatch(subbed, dictionaries()) {
+			token := password[m.I : m.J+1]
+
+			// Only count matches that actually contain a substitution
+			if strings.ToLower(string(token)) == m.MatchedWord {
+				continue
+			}
+
+			matchSub := map[rune]rune{}
+			for _, r := range token {
+				if letter, ok := sub[r]; ok {
+					matchSub[r] = letter
+				}
+			}
+
+			// Single-character l33t matches such as "1" for "i" add only noise
+			if len(token) <= 1 {
+				continue
+			}
+
+			// Ambiguous characters produce the same match under several substitution tables
+			key := fmt.Sprintf("%d:%d:%s:%s", m.I, m.J, m.Dictionary, m.MatchedWord)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			m.Token = string(token)
+			m.L33t = true
+			m.Sub = matchSub
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// relevantL33tTable restricts the l33t table to characters present in the password
+func relevantL33tTable(password []rune) map[rune][]rune {
+	present := map[rune]bool{}
+	for _, r := range password {
+		present[r] = true
+	}
+
+	table := map[rune][]rune{}
+	for letter, subs := range l33tTable {
+		for _, s := range subs {
+			if present[s] {
+				table[letter] = append(table[letter], s)
+			}
+		}
+	}
+	return table
+}
+
+// enumerateL33tSubs lists every way of mapping the l33t characters back to letters.
+// A character such as '1' may stand for either 'i' or 'l', so each ambiguous
+// character yields one substitution table per letter it could replace.
+func enumerateL33tSubs(table map[rune][]rune) []map[rune]rune {
+	options := map[rune][]rune{}
+	for _, letter := range sortedRuneKeys(table) {
+		for _, s := range table[letter] {
+			options[s] = append(options[s], letter)
+		}
+	}
+
+	subs := []map[rune]rune{{}}
+	for _, s := range sortedRuneKeys(options) {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range options[s] {
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[s] = letter
+				next = append(next, extended)
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+// spatialMatch finds runs of three or more adjacent keys on each keyboard layout,
+// counting changes of direction (turns) and keys typed with shift
+func spatialMatch(password []rune) []*Match {
+	var matches []*Match
+	for _, graph := range keyboardGraphs {
+		matches = append(matches, spatialMatchGraph(password, graph)...)
+	}
+	return matches
+}
+
+// spatialMatchGraph finds keyboard walks on a single layout
+func spatialMatchGraph(password []rune, graph *adjacencyGraph) []*Match {
+	var matches []*Match
+	i := 0
+	for i < len(password)-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shiftedCount := 0
+		if graph.shifted[password[i]] {
+			shiftedCount = 1
+		}
+
+		for {
+			found := false
+			if j < len(password) {
+				current := password[j]
+				for direction, adjacent := range graph.neighbours[password[j-1]] {
+					if !strings.ContainsRune(adjacent, current) {
+						continue
+					}
+					found = true
+					if graph.shifted[current] {
+						shiftedCount++
+					}
+					if lastDirection != direction {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+
+			// Walks of at least three keys are worth reporting
+			if j-i > 2 {
+				matches = append(matches, &Match{
+					Pattern:      "spatial",
+					I:            i,
+					J:            j - 1,
+					Token:        string(password[i:j]),
+					Graph:        graph.name,
+					Turns:        turns,
+					ShiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+	return matches
+}
+
+// repeatMatch finds substrings made of a repeated base, such as "aaa" or "abcabc"
+func repeatMatch(password []rune) []*Match {
+	var matches []*Match
+	i := 0
+	for i < len(password) {
+		bestLength, bestBase, bestCount := 0, 0, 0
+		for base := 1; base <= (len(password)-i)/2; base++ {
+			count := 1
+			for i+(count+1)*base <= len(password) &&
+				string(password[i+count*base:i+(count+1)*base]) == string(password[i:i+base]) {
+				count++
+			}
+			if count >= 2 && base*count > bestLength {
+				bestLength, bestBase, bestCount = base*count, base, count
+			}
+		}
+
+		if bestLength == 0 {
+			i++
+			continue
+		}
+
+		baseToken := password[i : i+bestBase]
+		baseAnalysis := mostGuessableMatchSequence(baseToken, omnimatch(baseToken))
+		matches = append(matches, &Match{
+			Pattern:     "repeat",
+			I:           i,
+			J:           i + bestLength - 1,
+			Token:       string(password[i : i+bestLength]),
+			BaseToken:   string(baseToken),
+			BaseGuesses: baseAnalysis.guesses,
+			RepeatCount: bestCount,
+		})
+		i += bestLength
+	}
+	return matches
+}
+
+// sequenceMatch finds runs of characters with a constant code point step, such as "abc" or "9753"
+func sequenceMatch(password []rune) []*Match {
+	if len(password) <= 1 {
+		return nil
+	}
+
+	var matches []*Match
+	update := func(i, j, delta int) {
+		if j-i <= 1 && abs(delta) != 1 {
+			return
+		}
+		if abs(delta) == 0 || abs(delta) > maxSequenceDelta {
+			return
+		}
+
+		token := password[i : j+1]
+		name, space := "unicode", 26
+		switch {
+		case allRunes(token, func(r rune) bool { return 'a' <= r && r <= 'z' }):
+			name, space = "lower", 26
+		case allRunes(token, func(r rune) bool { return 'A' <= r && r <= 'Z' }):
+			name, space = "upper", 26
+		case allRunes(token, func(r rune) bool { return '0' <= r && r <= '9' }):
+			name, space = "digits", 10
+		}
+
+		matches = append(matches, &Match{
+			Pattern:       "sequence",
+			I:             i,
+			J:             j,
+			Token:         string(token),
+			SequenceName:  name,
+			SequenceSpace: space,
+			Ascending:     delta > 0,
+		})
+	}
+
+	i := 0
+	lastDelta := int(password[1] - password[0])
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k] - password[k-1])
+		if delta == lastDelta {
+			continue
+		}
+		j := k - 1
+		update(i, j, lastDelta)
+		i = j
+		lastDelta = delta
+	}
+	update(i, len(password)-1, lastDelta)
+
+	return matches
+}
+
+// regexMatch finds recent years
+func regexMatch(password []rune) []*Match {
+	var matches []*Match
+	for i := 0; i+4 <= len(password); i++ {
+		token := string(password[i : i+4])
+		year, err := strconv.Atoi(token)
+		if err != nil || !isDigits(password[i:i+4]) || year < 1900 || year > referenceYear {
+			continue
+		}
+		matches = append(matches, &Match{
+			Pattern:   "regex",
+			I:         i,
+			J:         i + 3,
+			Token:     token,
+			RegexName: "recent_year",
+		})
+	}
+	return matches
+}
+
+// dateMatch finds dates written with or without separators, such as "13/05/1990" or "130590"
+func dateMatch(password []rune) []*Match {
+	var matches []*Match
+
+	// Dates without separators, 4 to 8 digits long
+	for i := 0; i+4 <= len(password); i++ {
+		for j := i + 3; j <= i+7 && j < len(password); j++ {
+			token := password[i : j+1]
+			if !isDigits(token) {
+				break
+			}
+
+			var best *Match
+			for _, split := range dateSplits[len(token)] {
+				first, _ := strconv.Atoi(string(token[:split[0]]))
+				second, _ := strconv.Atoi(string(token[split[0]:split[1]]))
+				third, _ := strconv.Atoi(string(token[split[1]:]))
+				year, month, day, ok := mapIntsToDMY(first, second, third)
+				if !ok {
+					continue
+				}
+				if best == nil || abs(year-referenceYear) < abs(best.Year-referenceYear) {
+					best = &Match{Year: year, Month: month, Day: day}
+				}
+			}
+
+			if best != nil {
+				best.Pattern = "date"
+				best.I, best.J = i, j
+				best.Token = string(token)
+				matches = append(matches, best)
+			}
+		}
+	}
+
+	// Dates with a separator, 6 to 10 characters long
+	for i := 0; i+6 <= len(password); i++ {
+		for j := i + 5; j <= i+9 && j < len(password); j++ {
+			token := password[i : j+1]
+			first, separator, second, third, ok := splitSeparatedDate(token)
+			if !ok {
+				continue
+			}
+			year, month, day, ok := mapIntsToDMY(first, second, third)
+			if !ok {
+				continue
+			}
+			matches = append(matches, &Match{
+				Pattern:   "date",
+				I:         i,
+				J:         j,
+				Token:     string(token),
+				Separator: string(separator),
+				Year:      year,
+				Month:     month,
+				Day:       day,
+			})
+		}
+	}
+
+	// Drop dates that lie strictly inside a longer date
+	var filtered []*Match
+	for _, m := range matches {
+		inside := false
+		for _, other := range matches {
+			if m != other && other.I <= m.I && other.J >= m.J && (other.I != m.I || other.J != m.J) {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// splitSeparatedDate splits a token of the form 1-4 digits, separator, 1-2 digits,
+// the same separator, 1-4 digits
+func splitSeparatedDate(token []rune) (int, rune, int, int, bool) {
+	isSeparator := func(r rune) bool { return unicode.IsSpace(r) || strings.ContainsRune(`/\_.-`, r) }
+
+	firstEnd := 0
+	for firstEnd < len(token) && '0' <= token[firstEnd] && token[firstEnd] <= '9' {
+		firstEnd++
+	}
+	if firstEnd < 1 || firstEnd > 4 || firstEnd >= len(token) || !isSeparator(token[firstEnd]) {
+		return 0, 0, 0, 0, false
+	}
+	separator := token[firstEnd]
+
+	secondEnd := firstEnd + 1
+	for secondEnd < len(token) && '0' <= token[secondEnd] && token[secondEnd] <= '9' {
+		secondEnd++
+	}
+	secondLen := secondEnd - firstEnd - 1
+	if secondLen < 1 || secondLen > 2 || secondEnd >= len(token) || token[secondEnd] != separator {
+		return 0, 0, 0, 0, false
+	}
+
+	rest := token[secondEnd+1:]
+	if len(rest) < 1 || len(rest) > 4 || !isDigits(rest) {
+		return 0, 0, 0, 0, false
+	}
+
+	first, _ := strconv.Atoi(string(token[:firstEnd]))
+	second, _ := strconv.Atoi(string(token[firstEnd+1 : secondEnd]))
+	third, _ := strconv.Atoi(string(rest))
+	return first, separator, second, third, true
+}
+
+// mapIntsToDMY interprets three integers as a day, month and year in any plausible order
+func mapIntsToDMY(first, second, third int) (int, int, int, bool) {
+	ints := []int{first, second, third}
+	if second > 31 || second <= 0 {
+		return 0, 0, 0, false
+	}
+
+	over12, over31, under1 := 0, 0, 0
+	for _, n := range ints {
+		if (99 < n && n < dateMinYear) || n > dateMaxYear {
+			return 0, 0, 0, false
+		}
+		if n > 31 {
+			over31++
+		}
+		if n > 12 {
+			over12++
+		}
+		if n <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return 0, 0, 0, false
+	}
+
+	// Try the year at either end, first as a four-digit year
+	splits := [][3]int{{third, first, second}, {first, second, third}}
+	for _, s := range splits {
+		if dateMinYear <= s[0] && s[0] <= dateMaxYear {
+			month, day, ok := mapIntsToDM(s[1], s[2])
+			return s[0], month, day, ok
+		}
+	}
+
+	for _, s := range splits {
+		if month, day, ok := mapIntsToDM(s[1], s[2]); ok {
+			return twoToFourDigitYear(s[0]), month, day, true
+		}
+	}
+
+	return 0, 0, 0, false
+}
+
+// mapIntsToDM interprets two integers as a day and month in either order
+func mapIntsToDM(a, b int) (int, int, bool) {
+	for _, dm := range [][2]int{{a, b}, {b, a}} {
+		day, month := dm[0], dm[1]
+		if 1 <= day && day <= 31 && 1 <= month && month <= 12 {
+			return month, day, true
+		}
+	}
+	return 0, 0, false
+}
+
+// twoToFourDigitYear expands a two-digit year to the nearest plausible century
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+// reverseRunes returns a reversed copy of the runes
+func reverseRunes(runes []rune) []rune {
+	reversed := make([]rune, len(runes))
+	for i, r := range runes {
+		reversed[len(runes)-1-i] = r
+	}
+	return reversed
+}
+
+// isDigits reports whether every rune is an ASCII digit
+func isDigits(runes []rune) bool {
+	return allRunes(runes, func(r rune) bool { return '0' <= r && r <= '9' })
+}
+
+// allRunes reports whether every rune satisfies the predicate
+func allRunes(runes []rune, predicate func(rune) bool) bool {
+	for _, r := range runes {
+		if !predicate(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedRuneKeys returns the keys of a rune-keyed map in sorted order
+func sortedRuneKeys[V any](m map[rune]V) []rune {
+	keys := make([]rune, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(a, b int) bool { return keys[a] < keys[b] })
+	return keys
+}
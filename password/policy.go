@@ -0,0 +1,305 @@
+package password
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is a declarative set of password rules. A zero field leaves its
+// rule out, so a policy only checks what it sets.
+type Policy struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	MinLength int `json:"min_length,omitempty" yaml:"min_length,omitempty"`
+	MaxLength int `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	// RequiredClasses lists character classes that must all appear: lower,
+	// upper, letter, digit and special
+	RequiredClasses []string `json:"required_classes,omitempty" yaml:"required_classes,omitempty"`
+	// MinClasses is how many of lower, upper, digit and special must appear
+	MinClasses int `json:"min_classes,omitempty" yaml:"min_classes,omitempty"`
+	// MaxRepeat is the longest run of one repeated character allowed
+	MaxRepeat int `json:"max_repeat,omitempty" yaml:"max_repeat,omitempty"`
+	// Blocklist rejects passwords in the built-in common password dictionary,
+	// in BlockedWords, or found by the run's common list and breach checks
+	Blocklist    bool     `json:"blocklist,omitempty" yaml:"blocklist,omitempty"`
+	BlockedWords []string `json:"blocked_words,omitempty" yaml:"blocked_words,omitempty"`
+	// ContextWords may not appear anywhere in the password, such as the
+	// service or company name; words given at run time are added
+	ContextWords []string `json:"context_words,omitempty" yaml:"context_words,omitempty"`
+	CheckContext bool     `json:"check_context,omitempty" yaml:"check_context,omitempty"`
+	// MinGuessesLog10 is the fewest estimated guesses allowed, as a power of ten
+	MinGuessesLog10 float64 `json:"min_guesses_log10,omitempty" yaml:"min_guesses_log10,omitempty"`
+}
+
+// PolicyFacts holds what the rest of the analysis found out about a password
+type PolicyFacts struct {
+	GuessesLog10 float64
+	// Blocklisted names the list or corpus the password was found in, if any
+	Blocklisted string
+	// Context holds words tied to the account, such as the user name or e-mail address
+	Context []string
+}
+
+// RuleResult is the outcome of one policy rule
+type RuleResult struct {
+	Rule        string // the policy field that sets the rule, such as "min_length"
+	Description string
+	Passed      bool
+	Detail      string // what the password has, when it is worth saying
+}
+
+// PolicyResult is the outcome of checking a password against a policy
+type PolicyResult struct {
+	Policy string
+	Passed bool
+	Rules  []RuleResult
+}
+
+// Policies holds the built-in policies by name
+var Policies = map[string]Policy{
+	"nist": {
+		Name:         "NIST SP 800-63B",
+		Description:  "At least 15 characters for a password that is the only factor, up to 64 allowed, no composition rules, blocklisted and context-specific words rejected",
+		MinLength:    15,
+		MaxLength:    64,
+		Blocklist:    true,
+		CheckContext: true,
+	},
+	"pci-dss": {
+		Name:            "PCI DSS 4.0",
+		Description:     "Requirement 8.3.6: at least 12 characters containing both letters and digits",
+		MinLength:       12,
+		RequiredClasses: []string{"letter", "digit"},
+	},
+	"complexity": {
+		Name:         "Legacy complexity",
+		Description:  "At least 8 characters from three of the four character classes, not containing the account name",
+		MinLength:    8,
+		MinClasses:   3,
+		CheckContext: true,
+	},
+}
+
+// PolicyNames lists the built-in policies
+func PolicyNames() []string {
+	names := make([]string, 0, len(Policies))
+	for name := range Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// characterClasses are the classes a policy can require
+var characterClasses = []string{"lower", "upper", "letter", "digit", "special"}
+
+// LoadPolicy returns the built-in policy with the given name, or reads a
+// policy from a YAML or JSON file. A file may start from a built-in policy
+// with "base: <name>" and override some of its rules.
+func LoadPolicy(nameOrPath string) (*Policy, error) {
+	if p, ok := Policies[nameOrPath]; ok {
+		return &p, nil
+	}
+
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither a built-in policy (%s) nor a readable policy file: %w", nameOrPath, strings.Join(PolicyNames(), ", "), err)
+	}
+
+	unmarshal := yaml.Unmarshal
+	if strings.EqualFold(filepath.Ext(nameOrPath), ".json") {
+		unmarshal = json.Unmarshal
+	}
+
+	var header struct {
+		Base string `json:"base" yaml:"base"`
+	}
+	if err := unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("reading policy from %s: %w", nameOrPath, err)
+	}
+
+	var p Policy
+	if header.Base != "" {
+		base, ok := Policies[header.Base]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown base policy %q (available: %s)", nameOrPath, header.Base, strings.Join(PolicyNames(), ", "))
+		}
+		// Decoding writes into the base's lists, so they must not share storage with the preset
+		p = base
+		p.RequiredClasses = slices.Clone(base.RequiredClasses)
+		p.BlockedWords = slices.Clone(base.BlockedWords)
+		p.ContextWords = slices.Clone(base.ContextWords)
+	}
+	if err := unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("reading policy from %s: %w", nameOrPath, err)
+	}
+	if p.Name == "" {
+		p.Name = nameOrPath
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", nameOrPath, err)
+	}
+	return &p, nil
+}
+
+// validate checks a policy read from a file
+func (p *Policy) validate() error {
+	if p.MinLength < 0 || p.MaxLength < 0 || p.MinClasses < 0 || p.MaxRepeat < 0 || p.MinGuessesLog10 < 0 {
+		return fmt.Errorf("policy %q: limits must not be negative", p.Name)
+	}
+	if p.MaxLength > 0 && p.MaxLength < p.MinLength {
+		return fmt.Errorf("policy %q: max_length is below min_length", p.Name)
+	}
+	if p.MinClasses > 4 {
+		return fmt.Errorf("policy %q: min_classes cannot exceed 4", p.Name)
+	}
+	for _, class := range p.RequiredClasses {
+		if !slices.Contains(characterClasses, class) {
+			return fmt.Errorf("policy %q: unknown character class %q (available: %s)", p.Name, class, strings.Join(characterClasses, ", "))
+		}
+	}
+	return nil
+}
+
+// Check evaluates the password against every rule the policy sets
+func (p *Policy) Check(password string, facts PolicyFacts) PolicyResult {
+	result := PolicyResult{Policy: p.Name, Passed: true}
+	add := func(rule, description string, passed bool, detail string) {
+		result.Rules = append(result.Rules, RuleResult{Rule: rule, Description: description, Passed: passed, Detail: detail})
+		result.Passed = result.Passed && passed
+	}
+
+	a := Analyze(password)
+	hasLower, hasUpper, hasDigit, hasSpecial := a.CharacterClasses()
+	present := map[string]bool{
+		"lower":   hasLower,
+		"upper":   hasUpper,
+		"letter":  hasLower || hasUpper,
+		"digit":   hasDigit,
+		"special": hasSpecial,
+	}
+
+	if p.MinLength > 0 {
+		add("min_length", fmt.Sprintf("At least %d characters", p.MinLength), a.Length >= p.MinLength, fmt.Sprintf("has %d", a.Length))
+	}
+	if p.MaxLength > 0 {
+		add("max_length", fmt.Sprintf("At most %d characters", p.MaxLength), a.Length <= p.MaxLength, fmt.Sprintf("has %d", a.Length))
+	}
+	if len(p.RequiredClasses) > 0 {
+		var missing []string
+		for _, class := range p.RequiredClasses {
+			if !present[class] {
+				missing = append(missing, class)
+			}
+		}
+		detail := ""
+		if len(missing) > 0 {
+			detail = "missing " + strings.Join(missing, ", ")
+		}
+		add("required_classes", "Contains each of: "+strings.Join(p.RequiredClasses, ", "), len(missing) == 0, detail)
+	}
+	if p.MinClasses > 0 {
+		classes := 0
+		for _, has := range []bool{hasLower, hasUpper, hasDigit, hasSpecial} {
+			if has {
+				classes++
+			}
+		}
+		add("min_classes", fmt.Sprintf("Uses %d of lower, upper, digit and special", p.MinClasses), classes >= p.MinClasses, fmt.Sprintf("uses %d", classes))
+	}
+	if p.MaxRepeat > 0 {
+		run, char := longestRepeat(password)
+		detail := ""
+		if run > p.MaxRepeat {
+			detail = fmt.Sprintf("%q repeated %d times", char, run)
+		}
+		add("max_repeat", fmt.Sprintf("No character repeated more than %d times in a row", p.MaxRepeat), run <= p.MaxRepeat, detail)
+	}
+	if p.Blocklist || len(p.BlockedWords) > 0 {
+		source := p.blocklistSource(password, facts)
+		detail := ""
+		if source != "" {
+			detail = "found in " + source
+		}
+		add("blocklist", "Not a known common, breached or blocked password", source == "", detail)
+	}
+	if p.CheckContext || len(p.ContextWords) > 0 {
+		words := append(append([]string(nil), p.ContextWords...), facts.Context...)
+		word := containedContextWord(password, words)
+		detail := ""
+		if word != "" {
+			detail = fmt.Sprintf("contains %q", word)
+		}
+		add("context_words", "Contains no context-specific words", word == "", detail)
+	}
+	if p.MinGuessesLog10 > 0 {
+		add("min_guesses", fmt.Sprintf("Needs at least 10^%g guesses", p.MinGuessesLog10), facts.GuessesLog10 >= p.MinGuessesLog10, fmt.Sprintf("has 10^%.2f", facts.GuessesLog10))
+	}
+
+	return result
+}
+
+// blocklistSource names where the password is blocklisted, or returns ""
+func (p *Policy) blocklistSource(password string, facts PolicyFacts) string {
+	lower := strings.ToLower(password)
+	for _, word := range p.BlockedWords {
+		if strings.ToLower(word) == lower {
+			return "the policy's blocked words"
+		}
+	}
+	if !p.Blocklist {
+		return ""
+	}
+	if _, ok := dictionaries()["passwords"][lower]; ok {
+		return "the built-in common password dictionary"
+	}
+	return facts.Blocklisted
+}
+
+// minContextWordLength keeps short fragments such as "com" in an e-mail
+// address from blocking ordinary passwords
+const minContextWordLength = 4
+
+// containedContextWord returns the first context word, or part of one
+// between punctuation, that appears in the password regardless of case
+func containedContextWord(password string, words []string) string {
+	lower := strings.ToLower(password)
+	for _, word := range words {
+		parts := append([]string{word}, strings.FieldsFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+		for _, part := range parts {
+			if len([]rune(part)) >= minContextWordLength && strings.Contains(lower, strings.ToLower(part)) {
+				return part
+			}
+		}
+	}
+	return ""
+}
+
+// longestRepeat returns the longest run of one repeated character and that character
+func longestRepeat(password string) (int, string) {
+	best, bestChar := 0, ""
+	run, prev := 0, ""
+	for _, cluster := range graphemeClusters(password) {
+		char := string(cluster)
+		if char == prev {
+			run++
+		} else {
+			run, prev = 1, char
+		}
+		if run > best {
+			best, bestChar = run, char
+		}
+	}
+	return best, bestChar
+}
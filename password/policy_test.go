@@ -0,0 +1,179 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// failedRules lists the rules a password broke, with their details
+func failedRules(result PolicyResult) string {
+	var failed []string
+	for _, rule := range result.Rules {
+		if !rule.Passed {
+			failed = append(failed, rule.Rule+": "+rule.Detail)
+		}
+	}
+	return strings.Join(failed, "; ")
+}
+
+func TestPolicyPresets(t *testing.T) {
+	tests := []struct {
+		policy   string
+		password string
+		context  []string
+		failed   string
+	}{
+		{"nist", "correcthorsebatterystaple", nil, ""},
+		{"nist", "password", nil, "min_length: has 8; blocklist: found in the built-in common password dictionary"},
+		{"nist", strings.Repeat("ab", 33), nil, "max_length: has 66"},
+		{"nist", "alice-in-wonderland", []string{"alice@example.com"}, `context_words: contains "alice"`},
+		{"pci-dss", "longpassword12", nil, ""},
+		{"pci-dss", "onlyletterslong", nil, "required_classes: missing digit"},
+		{"pci-dss", "4815162342", nil, "min_length: has 10; required_classes: missing letter"},
+		{"complexity", "Passw0rd", nil, ""},
+		{"complexity", "password", nil, "min_classes: uses 1"},
+		{"complexity", "Bobsmith1", []string{"bobsmith"}, `context_words: contains "bobsmith"`},
+	}
+	for _, tt := range tests {
+		p, err := LoadPolicy(tt.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := p.Check(tt.password, PolicyFacts{GuessesLog10: 20, Context: tt.context})
+		if got := failedRules(result); got != tt.failed {
+			t.Errorf("%s, %q: failed %q, want %q", tt.policy, tt.password, got, tt.failed)
+		}
+		if result.Passed != (tt.failed == "") {
+			t.Errorf("%s, %q: Passed = %v", tt.policy, tt.password, result.Passed)
+		}
+	}
+}
+
+func TestPolicyRules(t *testing.T) {
+	p := &Policy{
+		Name:            "test",
+		MaxRepeat:       2,
+		Blocklist:       true,
+		BlockedWords:    []string{"Acme2024!"},
+		ContextWords:    []string{"roadrunner", "co"},
+		MinGuessesLog10: 10,
+	}
+	tests := []struct {
+		password string
+		facts    PolicyFacts
+		failed   string
+	}{
+		{"quiet-meadow-lamp", PolicyFacts{GuessesLog10: 12}, ""},
+		{"quiet-meadow-lamp", PolicyFacts{GuessesLog10: 9.5}, "min_guesses: has 10^9.50"},
+		{"baaad-meadow-lamp", PolicyFacts{GuessesLog10: 12}, `max_repeat: "a" repeated 3 times`},
+		// Blocked words match regardless of case
+		{"ACME2024!", PolicyFacts{GuessesLog10: 12}, "blocklist: found in the policy's blocked words"},
+		{"quiet-meadow-lamp", PolicyFacts{GuessesLog10: 12, Blocklisted: "rockyou.txt"}, "blocklist: found in rockyou.txt"},
+		// Context words shorter than four characters are ignored
+		{"cozy-RoadRunner", PolicyFacts{GuessesLog10: 12}, `context_words: contains "roadrunner"`},
+		// Words given at run time are split at punctuation
+		{"lamp-coyote-42", PolicyFacts{GuessesLog10: 12, Context: []string{"wile.e.coyote@acme.test"}}, `context_words: contains "coyote"`},
+	}
+	for _, tt := range tests {
+		if got := failedRules(p.Check(tt.password, tt.facts)); got != tt.failed {
+			t.Errorf("%q: failed %q, want %q", tt.password, got, tt.failed)
+		}
+	}
+
+	// A policy only checks the rules it sets
+	if result := (&Policy{Name: "empty"}).Check("a", PolicyFacts{}); !result.Passed || len(result.Rules) != 0 {
+		t.Errorf("empty policy: %+v, want no rules", result)
+	}
+}
+
+// TestLoadPolicyExample checks that the example file keeps the rules of the
+// NIST preset it extends, overrides some and leaves the preset untouched
+func TestLoadPolicyExample(t *testing.T) {
+	p, err := LoadPolicy("../examples/policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Policy{
+		Name:            "Acme Corp",
+		Description:     p.Description,
+		MinLength:       12,
+		MaxLength:       64,
+		Blocklist:       true,
+		BlockedWords:    []string{"Acme2024!"},
+		ContextWords:    []string{"acme", "roadrunner"},
+		CheckContext:    true,
+		MinGuessesLog10: 10,
+	}
+	if !reflect.DeepEqual(*p, want) {
+		t.Errorf("LoadPolicy = %+v, want %+v", *p, want)
+	}
+	if nist := Policies["nist"]; nist.MinLength != 15 || nist.ContextWords != nil || nist.BlockedWords != nil {
+		t.Errorf("loading the example changed the nist preset: %+v", nist)
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Policy
+	}{
+		{"plain.json", `{"name": "plain", "min_length": 10, "required_classes": ["upper"]}`,
+			Policy{Name: "plain", MinLength: 10, RequiredClasses: []string{"upper"}}},
+		// Lists replace the base's rather than adding to them
+		{"extends.yaml", "base: pci-dss\nrequired_classes: [upper]\n", Policy{
+			Name:            "PCI DSS 4.0",
+			Description:     Policies["pci-dss"].Description,
+			MinLength:       12,
+			RequiredClasses: []string{"upper"},
+		}},
+		// Without a name the policy is reported under its path
+		{"unnamed.yml", "min_classes: 2\n", Policy{MinClasses: 2}},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if tt.want.Name == "" {
+			tt.want.Name = path
+		}
+		p, err := LoadPolicy(path)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if !reflect.DeepEqual(*p, tt.want) {
+			t.Errorf("%s: LoadPolicy = %+v, want %+v", tt.name, *p, tt.want)
+		}
+	}
+	if classes := Policies["pci-dss"].RequiredClasses; !reflect.DeepEqual(classes, []string{"letter", "digit"}) {
+		t.Errorf("extending pci-dss changed its required classes to %v", classes)
+	}
+}
+
+func TestLoadPolicyInvalid(t *testing.T) {
+	tests := map[string]string{
+		"base.yaml":      "base: iso\n",
+		"negative.yaml":  "min_length: -1\n",
+		"range.yaml":     "min_length: 20\nmax_length: 10\n",
+		"classes.yaml":   "min_classes: 5\n",
+		"class.json":     `{"required_classes": ["emoji"]}`,
+		"malformed.yaml": "min_length: [\n",
+	}
+	dir := t.TempDir()
+	for name, data := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("%s: LoadPolicy succeeded", name)
+		}
+	}
+	if _, err := LoadPolicy(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "neither a built-in policy") {
+		t.Errorf("missing file: %v", err)
+	}
+}
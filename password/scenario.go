@@ -0,0 +1,141 @@
+package password
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OnlineUnthrottledRate is the guesses per second an attacker sustains
+// against a login with no rate limiting, bound by the network and server
+const OnlineUnthrottledRate = 10
+
+// DefaultPrecomputed is how many guesses the lookup tables of the salt-less
+// scenario cover: a few terabytes of rainbow or lookup tables
+const DefaultPrecomputed = 1e15
+
+// Throttle is the rate limit of a login: at most Attempts per Window
+type Throttle struct {
+	Attempts int
+	Window   time.Duration
+}
+
+// ParseThrottle reads a throttle written as "<attempts>/<window>", such as
+// "100/1h" or "5/30s"
+func ParseThrottle(s string) (Throttle, error) {
+	attempts, window, found := strings.Cut(s, "/")
+	if !found {
+		return Throttle{}, fmt.Errorf("invalid throttle %q: want <attempts>/<window>, such as 100/1h", s)
+	}
+	n, err := strconv.Atoi(attempts)
+	if err != nil || n < 1 {
+		return Throttle{}, fmt.Errorf("invalid throttle %q: attempts must be a positive number", s)
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Throttle{}, fmt.Errorf("invalid throttle %q: window must be a positive duration", s)
+	}
+	return Throttle{Attempts: n, Window: d}, nil
+}
+
+// Rate returns the guesses per second the throttle lets through
+func (t Throttle) Rate() float64 {
+	return float64(t.Attempts) / t.Window.Seconds()
+}
+
+// String writes the throttle the way it reads, such as "100 per 1h"
+func (t Throttle) String() string {
+	window := t.Window.String()
+	if strings.HasSuffix(window, "m0s") {
+		window = strings.TrimSuffix(window, "0s")
+	}
+	if strings.HasSuffix(window, "h0m") {
+		window = strings.TrimSuffix(window, "0m")
+	}
+	return fmt.Sprintf("%d per %s", t.Attempts, window)
+}
+
+// Scenario is one way an attacker gets to try guesses
+type Scenario struct {
+	Name        string
+	Description string
+	// GuessesPerSecond is the rate the attacker sustains
+	GuessesPerSecond float64
+	// Lockout is how many failed guesses lock the account; zero means never
+	Lockout int64
+	// Precomputed is how many of the first guesses a lookup table answers
+	// without any hashing
+	Precomputed float64
+}
+
+// ScenarioEstimate is how a password fares in one scenario
+type ScenarioEstimate struct {
+	Seconds *big.Float
+	// LockedOut is set when the account locks before the password is reached
+	LockedOut bool
+	// Precomputed is set when a lookup table already holds the password
+	Precomputed bool
+}
+
+// Estimate returns how long the attacker of the scenario needs for the given number of guesses
+func (s Scenario) Estimate(guesses *big.Int) ScenarioEstimate {
+	if s.Lockout > 0 && guesses.Cmp(big.NewInt(s.Lockout)) > 0 {
+		return ScenarioEstimate{Seconds: CrackSeconds(big.NewInt(s.Lockout), s.GuessesPerSecond), LockedOut: true}
+	}
+	if s.Precomputed > 0 && new(big.Float).SetInt(guesses).Cmp(big.NewFloat(s.Precomputed)) <= 0 {
+		return ScenarioEstimate{Seconds: new(big.Float), Precomputed: true}
+	}
+	return ScenarioEstimate{Seconds: CrackSeconds(guesses, s.GuessesPerSecond)}
+}
+
+// Scenarios returns the attack scenarios in order of increasing attacker
+// power: online against a throttled login, online without limits, offline
+// against a slow and a fast hash, and offline against an unsalted fast hash
+// with precomputed tables. The offline speeds are those of the attacker
+// profile for the named hashes; a scenario whose speed is zero, because the
+// profile has none for its hash, is left out.
+func Scenarios(throttle Throttle, lockout int64, slowHash string, slowSpeed float64, fastHash string, fastSpeed float64) []Scenario {
+	throttled := fmt.Sprintf("Online attack on a login limited to %s", throttle)
+	if lockout > 0 {
+		throttled += fmt.Sprintf(" that locks after %d failures", lockout)
+	}
+	scenarios := []Scenario{
+		{
+			Name:             "online-throttled",
+			Description:      throttled,
+			GuessesPerSecond: throttle.Rate(),
+			Lockout:          lockout,
+		},
+		{
+			Name:             "online-unthrottled",
+			Description:      "Online attack on a login without rate limiting",
+			GuessesPerSecond: OnlineUnthrottledRate,
+		},
+		{
+			Name:             "offline-slow",
+			Description:      "Offline attack on a stolen " + slowHash + " hash",
+			GuessesPerSecond: slowSpeed,
+		},
+		{
+			Name:             "offline-fast",
+			Description:      "Offline attack on a stolen " + fastHash + " hash",
+			GuessesPerSecond: fastSpeed,
+		},
+		{
+			Name:             "offline-unsalted",
+			Description:      "Offline attack on a leaked table of unsalted " + fastHash + " hashes, with precomputed lookup tables",
+			GuessesPerSecond: fastSpeed,
+			Precomputed:      DefaultPrecomputed,
+		},
+	}
+
+	kept := scenarios[:0]
+	for _, s := range scenarios {
+		if s.GuessesPerSecond > 0 {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
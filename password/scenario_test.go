@@ -0,0 +1,107 @@
+package password
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestParseThrottle(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Throttle
+		rate float64
+		text string
+	}{
+		{"100/1h", Throttle{100, time.Hour}, 100.0 / 3600, "100 per 1h"},
+		{"5/30s", Throttle{5, 30 * time.Second}, 5.0 / 30, "5 per 30s"},
+		{"10/90m", Throttle{10, 90 * time.Minute}, 10.0 / 5400, "10 per 1h30m"},
+	}
+	for _, tt := range tests {
+		got, err := ParseThrottle(tt.spec)
+		if err != nil {
+			t.Errorf("ParseThrottle(%q): %v", tt.spec, err)
+			continue
+		}
+		if got != tt.want || got.Rate() != tt.rate || got.String() != tt.text {
+			t.Errorf("ParseThrottle(%q) = %+v at %v per second, written %q; want %+v at %v, written %q",
+				tt.spec, got, got.Rate(), got.String(), tt.want, tt.rate, tt.text)
+		}
+	}
+
+	for _, spec := range []string{"100", "0/1h", "-1/1h", "many/1h", "100/", "100/hour", "100/0s"} {
+		if _, err := ParseThrottle(spec); err == nil {
+			t.Errorf("ParseThrottle(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestScenarioEstimate(t *testing.T) {
+	throttled := Scenario{GuessesPerSecond: 100.0 / 3600, Lockout: 1000}
+	unsalted := Scenario{GuessesPerSecond: 1e10, Precomputed: 1e15}
+
+	tests := []struct {
+		name     string
+		scenario Scenario
+		guesses  int64
+		seconds  float64
+		want     ScenarioEstimate
+	}{
+		// 100 guesses an hour, so 999 take 9.99 hours
+		{"below lockout", throttled, 999, 999 * 36, ScenarioEstimate{}},
+		{"at lockout", throttled, 1000, 1000 * 36, ScenarioEstimate{}},
+		// Past the lockout the attacker stops after 1000 guesses
+		{"above lockout", throttled, 1001, 1000 * 36, ScenarioEstimate{LockedOut: true}},
+		{"no lockout", Scenario{GuessesPerSecond: 10}, 1e12, 1e11, ScenarioEstimate{}},
+		{"in the tables", unsalted, 1e15, 0, ScenarioEstimate{Precomputed: true}},
+		{"past the tables", unsalted, 1e15 + 1, 1e5, ScenarioEstimate{}},
+	}
+	for _, tt := range tests {
+		got := tt.scenario.Estimate(big.NewInt(tt.guesses))
+		seconds, _ := got.Seconds.Float64()
+		if got.LockedOut != tt.want.LockedOut || got.Precomputed != tt.want.Precomputed {
+			t.Errorf("%s: locked out %v and precomputed %v, want %v and %v",
+				tt.name, got.LockedOut, got.Precomputed, tt.want.LockedOut, tt.want.Precomputed)
+		}
+		if diff := seconds - tt.seconds; diff > 1e-6*tt.seconds || diff < -1e-6*tt.seconds {
+			t.Errorf("%s: %v seconds, want %v", tt.name, seconds, tt.seconds)
+		}
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	throttle := Throttle{Attempts: 5, Window: time.Minute}
+	scenarios := Scenarios(throttle, 10, "bcrypt", 1e5, "MD5", 1e10)
+
+	want := []struct {
+		name    string
+		speed   float64
+		lockout int64
+	}{
+		{"online-throttled", 5.0 / 60, 10},
+		{"online-unthrottled", OnlineUnthrottledRate, 0},
+		{"offline-slow", 1e5, 0},
+		{"offline-fast", 1e10, 0},
+		{"offline-unsalted", 1e10, 0},
+	}
+	if len(scenarios) != len(want) {
+		t.Fatalf("%d scenarios, want %d", len(scenarios), len(want))
+	}
+	for i, w := range want {
+		s := scenarios[i]
+		if s.Name != w.name || s.GuessesPerSecond != w.speed || s.Lockout != w.lockout {
+			t.Errorf("scenario %d = %s at %v with lockout %d, want %s at %v with lockout %d",
+				i, s.Name, s.GuessesPerSecond, s.Lockout, w.name, w.speed, w.lockout)
+		}
+	}
+	if got := scenarios[0].Description; got != "Online attack on a login limited to 5 per 1m that locks after 10 failures" {
+		t.Errorf("throttled description %q", got)
+	}
+
+	// Without a speed for the slow hash its scenario is left out
+	for _, s := range Scenarios(throttle, 0, "bcrypt", 0, "MD5", 1e10) {
+		if s.Name == "offline-slow" {
+			t.Error("offline-slow kept without a speed")
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package password
+
+import (
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Constants of the guess model, following zxcvbn
+const (
+	bruteforceCardinality             = 10
+	minGuessesBeforeGrowingSequence   = 10000
+	minSubmatchGuessesSingleChar      = 10
+	minSubmatchGuessesMultiChar       = 50
+	minYearSpace                      = 20
+	daysPerYear                       = 365
+	dateSeparatorGuessMultiplier      = 4
+	reversedWordGuessMultiplier       = 2
+	sequenceObviousStartGuesses       = 4
+	sequenceDigitStartGuesses         = 10
+	sequenceOtherStartGuesses         = 26
+	descendingSequenceGuessMultiplier = 2
+)
+
+// GuessEstimate is the result of the pattern-aware guess estimator
+type GuessEstimate struct {
+	// Guesses is the estimated number of guesses an attacker needs
+	Guesses *big.Int
+	// GuessesLog10 is log10 of Guesses
+	GuessesLog10 float64
+	// Sequence is the cheapest way of covering the password with matches
+	Sequence []*Match
+	// KeyboardWalks lists every keyboard walk found, whether or not it is part of Sequence
+	KeyboardWalks []*Match
+}
+
+// HasBruteforce reports whether the cheapest match sequence brute-forces any part of the password
+func (e GuessEstimate) HasBruteforce() bool {
+	for _, m := range e.Sequence {
+		if m.Pattern == "bruteforce" {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimateGuesses decomposes the password into guessable patterns (dictionary
+// words, repeats, sequences, keyboard walks, dates, l33t substitutions) and
+// returns the minimum number of guesses over the best sequence of matches
+func EstimateGuesses(password string) GuessEstimate {
+	runes := []rune(password)
+
+	// The search is superlinear, so only the runes searched for patterns go
+	// through it and the rest is one brute-force match appended to the sequence
+	head := runes
+	if len(head) > maxMatchLength {
+		head = head[:maxMatchLength]
+	}
+	matches := omnimatch(head)
+	result := mostGuessableMatchSequence(head, matches)
+	if len(head) < len(runes) {
+		tail := bruteforceMatch(runes, len(head), len(runes)-1)
+		l := float64(len(result.sequence) + 1)
+		result.guesses *= l * estimateMatchGuesses(tail, len(runes))
+		if math.IsInf(result.guesses, 1) {
+			result.guesses = math.MaxFloat64
+		}
+		result.sequence = append(result.sequence, tail)
+	}
+
+	var walks []*Match
+	for _, m := range matches {
+		if m.Pattern == "spatial" {
+			walks = append(walks, m)
+		}
+	}
+
+	guesses, _ := big.NewFloat(result.guesses).Int(nil)
+	return GuessEstimate{
+		Guesses:       guesses,
+		GuessesLog10:  math.Log10(result.guesses),
+		Sequence:      result.sequence,
+		KeyboardWalks: walks,
+	}
+}
+
+// matchSequence is the outcome of the search for the cheapest match sequence
+type matchSequence struct {
+	guesses  float64
+	sequence []*Match
+}
+
+// mostGuessableMatchSequence finds the sequence of non-overlapping matches
+// covering the password that minimises the total number of guesses. Gaps
+// between matches are filled with brute-force matches.
+//
+// A sequence of l matches costs l! * (product of match guesses), plus
+// minGuessesBeforeGrowingSequence^(l-1) to favour fewer, longer matches.
+func mostGuessableMatchSequence(password []rune, matches []*Match) matchSequence {
+	n := len(password)
+	if n == 0 {
+		return matchSequence{guesses: 1}
+	}
+
+	matchesByJ := make([][]*Match, n)
+	for _, m := range matches {
+		if m.J < n {
+			matchesByJ[m.J] = append(matchesByJ[m.J], m)
+		}
+	}
+	for _, list := range matchesByJ {
+		sortMatches(list)
+	}
+
+	// For each end position k and sequence length l: the best last match,
+	// the product of match guesses and the overall score
+	optimalM := make([]map[int]*Match, n)
+	optimalPi := make([]map[int]float64, n)
+	optimalG := make([]map[int]float64, n)
+	for k := range optimalM {
+		optimalM[k] = map[int]*Match{}
+		optimalPi[k] = map[int]float64{}
+		optimalG[k] = map[int]float64{}
+	}
+
+	update := func(m *Match, l int) {
+		k := m.J
+		pi := estimateMatchGuesses(m, n)
+		if l > 1 {
+			pi *= optimalPi[m.I-1][l-1]
+		}
+		g := factorial(l)*pi + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+
+		// Keep the candidate only if no shorter or equal-length sequence ending here is as cheap
+		for competingL, competingG := range optimalG[k] {
+			if competingL > l {
+				continue
+			}
+			if competingG <= g {
+				return
+			}
+		}
+		optimalG[k][l] = g
+		optimalM[k][l] = m
+		optimalPi[k][l] = pi
+	}
+
+	bruteforceUpdate := func(k int) {
+		update(bruteforceMatch(password, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			m := bruteforceMatch(password, i, k)
+			for _, l := range sortedLengths(optimalM[i-1]) {
+				// Two adjacent brute-force matches are never better than one longer one
+				if optimalM[i-1][l].Pattern == "bruteforce" {
+					continue
+				}
+				update(m, l+1)
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range matchesByJ[k] {
+			if m.I > 0 {
+				for _, l := range sortedLengths(optimalM[m.I-1]) {
+					update(m, l+1)
+				}
+			} else {
+				update(m, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	// Unwind the cheapest sequence ending at the last character
+	bestL, bestG := 0, math.Inf(1)
+	for l, g := range optimalG[n-1] {
+		if g < bestG || (g == bestG && l < bestL) {
+			bestL, bestG = l, g
+		}
+	}
+
+	var sequence []*Match
+	for k, l := n-1, bestL; k >= 0; l-- {
+		m := optimalM[k][l]
+		sequence = append([]*Match{m}, sequence...)
+		k = m.I - 1
+	}
+
+	if math.IsInf(bestG, 1) {
+		bestG = math.MaxFloat64
+	}
+	return matchSequence{guesses: bestG, sequence: sequence}
+}
+
+// sortedLengths returns the sequence lengths recorded for a position in ascending order
+func sortedLengths(m map[int]*Match) []int {
+	lengths := make([]int, 0, len(m))
+	for l := range m {
+		lengths = append(lengths, l)
+	}
+	sort.Ints(lengths)
+	return lengths
+}
+
+// bruteforceMatch covers password[i..j] with a brute-force match
+func bruteforceMatch(password []rune, i, j int) *Match {
+	return &Match{
+		Pattern: "bruteforce",
+		I:       i,
+		J:       j,
+		Token:   string(password[i : j+1]),
+	}
+}
+
+// estimateMatchGuesses computes and caches the number of guesses for a match
+// within a password of the given length
+func estimateMatchGuesses(m *Match, passwordLength int) float64 {
+	if m.Guesses != 0 {
+		return m.Guesses
+	}
+
+	tokenLength := len([]rune(m.Token))
+	minGuesses := 1.0
+	if tokenLength < passwordLength {
+		minGuesses = minSubmatchGuessesMultiChar
+		if tokenLength == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var guesses float64
+	switch m.Pattern {
+	case "bruteforce":
+		guesses = bruteforceGuesses(tokenLength)
+	case "dictionary":
+		guesses = dictionaryGuesses(m)
+	case "spatial":
+		guesses = spatialGuesses(m)
+	case "repeat":
+		guesses = m.BaseGuesses * float64(m.RepeatCount)
+	case "sequence":
+		guesses = sequenceGuesses(m)
+	case "regex":
+		guesses = regexGuesses(m)
+	case "date":
+		guesses = dateGuesses(m)
+	}
+
+	m.Guesses = math.Max(guesses, minGuesses)
+	return m.Guesses
+}
+
+// bruteforceGuesses assumes an attacker tries every string of the token's length
+func bruteforceGuesses(tokenLength int) float64 {
+	guesses := math.Pow(bruteforceCardinality, float64(tokenLength))
+	if math.IsInf(guesses, 1) {
+		guesses = math.MaxFloat64
+	}
+
+	// A brute-force match must never be cheaper than any other match of the same length
+	minGuesses := float64(minSubmatchGuessesMultiChar + 1)
+	if tokenLength == 1 {
+		minGuesses = minSubmatchGuessesSingleChar + 1
+	}
+	return math.Max(guesses, minGuesses)
+}
+
+// dictionaryGuesses scales the word's rank by its capitalisation, l33t and reversal variations
+func dictionaryGuesses(m *Match) float64 {
+	guesses := float64(m.Rank) * uppercaseVariations(m.Token) * l33tVariations(m)
+	if m.Reversed {
+		guesses *= reversedWordGuessMultiplier
+	}
+	return guesses
+}
+
+// uppercaseVariations counts the capitalisation patterns an attacker would try
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 1
+	}
+
+	// Capitalising the first letter, the last letter or every letter is common
+	first, last := runes[0], runes[len(runes)-1]
+	if (unicode.IsUpper(first) && upper == 1) || (unicode.IsUpper(last) && upper == 1) || lower == 0 {
+		return 2
+	}
+
+	return sumBinomials(upper+lower, min(upper, lower))
+}
+
+// l33tVariations counts the substitution patterns an attacker would try
+func l33tVariations(m *Match) float64 {
+	if !m.L33t {
+		return 1
+	}
+
+	variations := 1.0
+	token := strings.ToLower(m.Token)
+	for subbed, unsubbed := range m.Sub {
+		s, u := 0, 0
+		for _, r := range token {
+			switch r {
+			case subbed:
+				s++
+			case unsubbed:
+				u++
+			}
+		}
+		if s == 0 || u == 0 {
+			// Either every instance is substituted or none is
+			variations *= 2
+		} else {
+			variations *= sumBinomials(s+u, min(s, u))
+		}
+	}
+	return variations
+}
+
+// spatialGuesses counts the keyboard walks of the same length with at most as many turns
+func spatialGuesses(m *Match) float64 {
+	graph := keyboardGraph(m.Graph)
+	s, d := graph.startingPositions, graph.averageDegree
+
+	guesses := 0.0
+	length := len([]rune(m.Token))
+	for i := 2; i <= length; i++ {
+		possibleTurns := min(m.Turns, i-1)
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += binomial(i-1, j-1) * s * math.Pow(d, float64(j))
+		}
+	}
+
+	// Add the ways the shifted keys could have been distributed
+	if m.ShiftedCount > 0 {
+		shifted, unshifted := m.ShiftedCount, length-m.ShiftedCount
+		if shifted == 0 || unshifted == 0 {
+			guesses *= 2
+		} else {
+			guesses *= sumBinomials(shifted+unshifted, min(shifted, unshifted))
+		}
+	}
+	return guesses
+}
+
+// sequenceGuesses rates a sequence by its starting character, direction and length
+func sequenceGuesses(m *Match) float64 {
+	first := []rune(m.Token)[0]
+
+	var base float64
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		base = sequenceObviousStartGuesses
+	case '0' <= first && first <= '9':
+		base = sequenceDigitStartGuesses
+	default:
+		base = sequenceOtherStartGuesses
+	}
+	if !m.Ascending {
+		base *= descendingSequenceGuessMultiplier
+	}
+	return base * float64(len([]rune(m.Token)))
+}
+
+// regexGuesses rates a recent year by its distance from the current year
+func regexGuesses(m *Match) float64 {
+	year := 0
+	for _, r := range m.Token {
+		year = year*10 + int(r-'0')
+	}
+	return float64(max(abs(year-referenceYear), minYearSpace))
+}
+
+// dateGuesses rates a date by how far its year is from the current year
+func dateGuesses(m *Match) float64 {
+	guesses := float64(max(abs(m.Year-referenceYear), minYearSpace) * daysPerYear)
+	if m.Separator != "" {
+		guesses *= dateSeparatorGuessMultiplier
+	}
+	return guesses
+}
+
+// keyboardGraph looks up a keyboard layout by name
+func keyboardGraph(name string) *adjacencyGraph {
+	for _, graph := range keyboardGraphs {
+		if graph.name == name {
+			return graph
+		}
+	}
+	return keyboardGraphs[0]
+}
+
+// binomial returns n choose k
+func binomial(n, k int) float64 {
+	if k > n || k < 0 {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+// sumBinomials returns the sum of n choose i for i from 1 to k
+func sumBinomials(n, k int) float64 {
+	sum := 0.0
+	for i := 1; i <= k; i++ {
+		sum += binomial(n, i)
+	}
+	return sum
+}
+
+// factorial returns n!
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
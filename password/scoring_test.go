@@ -0,0 +1,115 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestEstimateGuesses checks the estimator against guess counts worked out by
+// hand with zxcvbn's formulas and the ranks in the embedded lists. A sequence
+// of l matches costs l! times the product of their guesses, plus 10000^(l-1).
+func TestEstimateGuesses(t *testing.T) {
+	// Pin the year so the counts for dates and years do not change over time
+	defer func(year int) { referenceYear = year }(referenceYear)
+	referenceYear = 2017
+
+	tests := []struct {
+		password string
+		want     float64
+		patterns []string
+	}{
+		// "password" is rank 1, with 2 capitalisations, raised to the 50
+		// guesses of a submatch, then 10^4 to brute-force "123!":
+		// 2! * 50 * 10^4 + 10^4
+		{"Password123!", 1010000, []string{"dictionary", "bruteforce"}},
+		// Ranks 1525, 494, 3845 and 14066: 4! * 1525 * 494 * 3845 * 14066 + 10^12
+		{"correcthorsebatterystaple", 978856195108000, []string{"dictionary", "dictionary", "dictionary", "dictionary"}},
+		// "a" is rank 5 on its own, so 1! * 5 + 1 = 6 for the base, repeated 6 times: 36 + 1
+		{"aaaaaa", 37, []string{"repeat"}},
+		// An obvious start of 4, times a length of 6: 24 + 1
+		{"abcdef", 25, []string{"sequence"}},
+		// Descending doubles the obvious start: 4 * 2 * 5 + 1
+		{"zyxwv", 41, []string{"sequence"}},
+		// 18 years from 2017 raised to the minimum of 20, times 365 days,
+		// times 4 for the separator: 29200 + 1
+		{"11/22/1999", 29201, []string{"date"}},
+		// 27 years from 2017: 27 + 1
+		{"1990", 28, []string{"regex"}},
+		// Rank 1, times 2 for each of the fully substituted '@' and '0': 4 + 1
+		{"p@ssw0rd", 5, []string{"dictionary"}},
+		// Rank 1, times 2 for the reversal: 2 + 1
+		{"drowssap", 3, []string{"dictionary"}},
+	}
+	for _, tt := range tests {
+		got := EstimateGuesses(tt.password)
+		if want := math.Log10(tt.want); math.Abs(got.GuessesLog10-want) > 1e-9 {
+			t.Errorf("%q: GuessesLog10 = %v, want %v", tt.password, got.GuessesLog10, want)
+		}
+		if got.Guesses.Int64() != int64(tt.want) {
+			t.Errorf("%q: Guesses = %v, want %v", tt.password, got.Guesses, int64(tt.want))
+		}
+		var patterns []string
+		for _, m := range got.Sequence {
+			patterns = append(patterns, m.Pattern)
+		}
+		if strings.Join(patterns, " ") != strings.Join(tt.patterns, " ") {
+			t.Errorf("%q: sequence %v, want %v", tt.password, patterns, tt.patterns)
+		}
+	}
+}
+
+func TestEstimateGuessesMatches(t *testing.T) {
+	tests := []struct {
+		password string
+		want     Match
+	}{
+		{"p@ssw0rd", Match{Pattern: "dictionary", MatchedWord: "password", Rank: 1, L33t: true}},
+		{"drowssap", Match{Pattern: "dictionary", MatchedWord: "password", Rank: 1, Reversed: true}},
+		{"aaaaaa", Match{Pattern: "repeat", BaseToken: "a", RepeatCount: 6}},
+		{"abcdef", Match{Pattern: "sequence", SequenceName: "lower", Ascending: true}},
+		{"11/22/1999", Match{Pattern: "date", Separator: "/", Year: 1999, Month: 11, Day: 22}},
+	}
+	for _, tt := range tests {
+		seq := EstimateGuesses(tt.password).Sequence
+		if len(seq) != 1 {
+			t.Errorf("%q: sequence of %d matches, want 1", tt.password, len(seq))
+			continue
+		}
+		m := *seq[0]
+		if m.Pattern != tt.want.Pattern || m.MatchedWord != tt.want.MatchedWord || m.Rank != tt.want.Rank ||
+			m.L33t != tt.want.L33t || m.Reversed != tt.want.Reversed || m.BaseToken != tt.want.BaseToken ||
+			m.RepeatCount != tt.want.RepeatCount || m.SequenceName != tt.want.SequenceName ||
+			m.Ascending != tt.want.Ascending || m.Separator != tt.want.Separator ||
+			m.Year != tt.want.Year || m.Month != tt.want.Month || m.Day != tt.want.Day {
+			t.Errorf("%q: match %+v, want %+v", tt.password, m, tt.want)
+		}
+	}
+}
+
+// TestEstimateGuessesLongPassword checks that only the first maxMatchLength
+// runes are searched for patterns, and the rest is brute-forced as one match
+func TestEstimateGuessesLongPassword(t *testing.T) {
+	// The head is "a" repeated maxMatchLength times, at 6 * 100 + 1 guesses,
+	// and the tail 5 more runes at 10^5, in a sequence of 2 matches
+	got := EstimateGuesses(strings.Repeat("a", maxMatchLength) + "bcdef")
+	if len(got.Sequence) != 2 {
+		t.Fatalf("sequence of %d matches, want 2", len(got.Sequence))
+	}
+	tail := got.Sequence[1]
+	if tail.Pattern != "bruteforce" || tail.I != maxMatchLength || tail.J != maxMatchLength+4 || tail.Token != "bcdef" {
+		t.Errorf("tail match %+v, want a brute force of \"bcdef\"", *tail)
+	}
+	if want := int64(601 * 2 * 1e5); got.Guesses.Int64() != want {
+		t.Errorf("Guesses = %v, want %d", got.Guesses, want)
+	}
+
+	// Far past the limit the count saturates rather than overflowing
+	got = EstimateGuesses(strings.Repeat("x", 10000))
+	if last := got.Sequence[len(got.Sequence)-1]; last.I != maxMatchLength || last.J != 9999 {
+		t.Errorf("tail covers runes %d to %d, want %d to 9999", last.I, last.J, maxMatchLength)
+	}
+	if math.IsInf(got.GuessesLog10, 0) || math.IsNaN(got.GuessesLog10) {
+		t.Errorf("GuessesLog10 = %v, want a finite count", got.GuessesLog10)
+	}
+}
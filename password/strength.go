@@ -1,5 +1,8 @@
 package password
 
+// StrengthRatings lists the ratings returned by GetStrength from weakest to strongest
+var StrengthRatings = []string{"Weak", "Medium", "Strong", "Very Strong"}
+
 // GetStrength determines the strength rating of a password
 func GetStrength(password string, length int, hasLower, hasUpper, hasDigit, hasSpecial bool) string {
 	// Count the character types used
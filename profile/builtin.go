@@ -0,0 +1,72 @@
+package profile
+
+// Builtin holds the default profiles, used when no profile file is given.
+// Speeds are in hashes per second with the default cost parameters.
+var Builtin = []*Profile{
+	{
+		Name:        "Slow PC",
+		Description: "An older desktop cracking on its CPU",
+		Devices:     1,
+		Speeds: map[string]float64{
+			"MD5":                5000000, // 5 million/sec
+			"SHA-1":              3000000, // 3 million/sec
+			"SHA-256":            1000000, // 1 million/sec
+			"SHA-512":            500000,  // 500 thousand/sec
+			"SHA3-256":           400000,  // 400 thousand/sec
+			"NTLM":               8000000, // 8 million/sec
+			"md5crypt":           2000,    // 2 thousand/sec
+			"sha256crypt":        200,     // 200/sec
+			"sha512crypt":        150,     // 150/sec
+			"PBKDF2-HMAC-SHA256": 2,       // 2/sec at 600,000 iterations
+			"PBKDF2-HMAC-SHA512": 2,       // 2/sec at 210,000 iterations
+			"bcrypt":             3,       // 3/sec
+			"scrypt":             1,       // 1/sec at N=32768, r=8, p=1
+			"Argon2i":            2,       // 2/sec at 19 MiB, t=2
+			"Argon2id":           2,       // 2/sec at 19 MiB, t=2
+		},
+	},
+	{
+		Name:        "Normal PC",
+		Description: "A current desktop with a mid-range graphics card",
+		Devices:     1,
+		Speeds: map[string]float64{
+			"MD5":                500000000, // 500 million/sec
+			"SHA-1":              200000000, // 200 million/sec
+			"SHA-256":            100000000, // 100 million/sec
+			"SHA-512":            40000000,  // 40 million/sec
+			"SHA3-256":           30000000,  // 30 million/sec
+			"NTLM":               800000000, // 800 million/sec
+			"md5crypt":           200000,    // 200 thousand/sec
+			"sha256crypt":        5000,      // 5 thousand/sec
+			"sha512crypt":        3000,      // 3 thousand/sec
+			"PBKDF2-HMAC-SHA256": 20,        // 20/sec
+			"PBKDF2-HMAC-SHA512": 15,        // 15/sec
+			"bcrypt":             5,         // 5/sec
+			"scrypt":             3,         // 3/sec
+			"Argon2i":            4,         // 4/sec
+			"Argon2id":           4,         // 4/sec
+		},
+	},
+	{
+		Name:        "High-end GPU",
+		Description: "A workstation with a high-end graphics card",
+		Devices:     1,
+		Speeds: map[string]float64{
+			"MD5":                10000000000, // 10 billion/sec
+			"SHA-1":              5000000000,  // 5 billion/sec
+			"SHA-256":            1000000000,  // 1 billion/sec
+			"SHA-512":            400000000,   // 400 million/sec
+			"SHA3-256":           300000000,   // 300 million/sec
+			"NTLM":               20000000000, // 20 billion/sec
+			"md5crypt":           5000000,     // 5 million/sec
+			"sha256crypt":        200000,      // 200 thousand/sec
+			"sha512crypt":        100000,      // 100 thousand/sec
+			"PBKDF2-HMAC-SHA256": 1500,        // 1,500/sec
+			"PBKDF2-HMAC-SHA512": 800,         // 800/sec
+			"bcrypt":             10,          // 10/sec (GPUs aren't great for bcrypt)
+			"scrypt":             50,          // 50/sec (memory-hard)
+			"Argon2i":            20,          // 20/sec (memory-hard)
+			"Argon2id":           20,          // 20/sec (memory-hard)
+		},
+	},
+}
@@ -0,0 +1,246 @@
+package profile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sharafdin/crackulator/hash"
+)
+
+// hashcatModes maps hashcat hash modes to hash.Types names
+var hashcatModes = map[int][]string{
+	0:     {"MD5"},
+	100:   {"SHA-1"},
+	1400:  {"SHA-256"},
+	1700:  {"SHA-512"},
+	17400: {"SHA3-256"},
+	1000:  {"NTLM"},
+	500:   {"md5crypt"},
+	7400:  {"sha256crypt"},
+	1800:  {"sha512crypt"},
+	10900: {"PBKDF2-HMAC-SHA256"},
+	12100: {"PBKDF2-HMAC-SHA512"},
+	3200:  {"bcrypt"},
+	8900:  {"scrypt"},
+	34000: {"Argon2i", "Argon2id"},
+}
+
+// Iteration counts hashcat benchmarks iterated modes with, used when the
+// output does not report them (machine-readable mode never does)
+var hashcatBenchmarkIterations = map[int]int{
+	500:   1000,
+	7400:  5000,
+	1800:  5000,
+	10900: 999,
+	12100: 999,
+	3200:  32,
+}
+
+// Rounds of the crypt schemes that hash.Params has no setting for
+var cryptDefaultRounds = map[string]int{
+	"md5crypt":    1000,
+	"sha256crypt": 5000,
+	"sha512crypt": 5000,
+}
+
+// Parameters hashcat's benchmark hashes use for the memory-hard modes
+var (
+	hashcatScryptParams = hash.Params{ScryptN: 16384, ScryptR: 8, ScryptP: 1}
+	hashcatArgon2Params = hash.Params{Argon2Time: 3, Argon2Memory: 65536, Argon2Threads: 1}
+)
+
+var (
+	// "* Hash-Mode 3200 (bcrypt $2*$, Blowfish (Unix)) [Iterations: 32]" and the older "Hashmode: 3200 - bcrypt ..."
+	hashcatModeLine       = regexp.MustCompile(`^\*?\s*Hash-?[Mm]ode:?\s+(\d+)`)
+	hashcatIterationsPart = regexp.MustCompile(`\[Iterations:\s*(\d+)\]`)
+	// "Speed.#1.........:   164.1 GH/s (57.07ms) @ ..." and the older "Speed.Dev.#1.....:"
+	hashcatSpeedLine = regexp.MustCompile(`^Speed\.(?:Dev\.)?#(\d+|\*)\.*:\s*([\d.]+)\s*([kMGTP]?)H/s`)
+	// "* Device #1: NVIDIA GeForce RTX 4090, 23867/24563 MB, 128MCU"
+	hashcatDeviceLine = regexp.MustCompile(`^\*\s*Device #\d+:\s*([^,]+)`)
+)
+
+// Multipliers of hashcat's speed units
+var hashcatUnits = map[string]float64{"": 1, "k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15}
+
+// hashcatResult accumulates the measurements of one hash mode
+type hashcatResult struct {
+	iterations int
+	devices    float64 // sum of the per-device speeds
+	count      int     // number of devices measured
+	total      float64 // the "Speed.#*" line, when hashcat printed one
+}
+
+// LoadHashcat reads a saved "hashcat -b" run from a file. See ParseHashcat.
+func LoadHashcat(path, name string) (*Profile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	p, err := ParseHashcat(f, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// ParseHashcat turns the output of "hashcat -b", in the normal or the
+// --machine-readable format, into a profile. Speeds are averaged over the
+// devices in the run, which become the profile's device count, and rescaled
+// from the iteration counts hashcat benchmarks with to the default cost
+// parameters. Modes without a hash.Types equivalent are skipped.
+func ParseHashcat(r io.Reader, name string) (*Profile, error) {
+	results := map[int]*hashcatResult{}
+	var order []int
+	var devices []string
+
+	result := func(mode int) *hashcatResult {
+		if results[mode] == nil {
+			results[mode] = &hashcatResult{}
+			order = append(order, mode)
+		}
+		return results[mode]
+	}
+
+	current := -1
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if m := hashcatDeviceLine.FindStringSubmatch(line); m != nil {
+			if !strings.Contains(line, "skipped") {
+				devices = append(devices, strings.TrimSpace(m[1]))
+			}
+			continue
+		}
+
+		if m := hashcatModeLine.FindStringSubmatch(line); m != nil {
+			current, _ = strconv.Atoi(m[1])
+			if it := hashcatIterationsPart.FindStringSubmatch(line); it != nil {
+				result(current).iterations, _ = strconv.Atoi(it[1])
+			}
+			continue
+		}
+
+		if m := hashcatSpeedLine.FindStringSubmatch(line); m != nil && current >= 0 {
+			speed, err := strconv.ParseFloat(m[2], 64)
+			if err != nil {
+				continue
+			}
+			speed *= hashcatUnits[m[3]]
+			if m[1] == "*" {
+				result(current).total = speed
+			} else {
+				result(current).devices += speed
+				result(current).count++
+			}
+			continue
+		}
+
+		// Machine-readable lines: device:mode:...:hashes per second
+		if fields := strings.Split(line, ":"); len(fields) >= 6 {
+			_, deviceErr := strconv.Atoi(fields[0])
+			mode, modeErr := strconv.Atoi(fields[1])
+			speed, speedErr := strconv.ParseFloat(fields[len(fields)-1], 64)
+			if deviceErr == nil && modeErr == nil && speedErr == nil {
+				result(mode).devices += speed
+				result(mode).count++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p := &Profile{
+		Name:        name,
+		Description: "Imported from a hashcat benchmark",
+		Devices:     max(len(devices), 1),
+		Speeds:      map[string]float64{},
+	}
+	if len(devices) > 0 {
+		p.Description += " on " + strings.Join(devices, ", ")
+	}
+
+	for _, mode := range order {
+		names, ok := hashcatModes[mode]
+		if !ok {
+			continue
+		}
+		res := results[mode]
+		speed := res.total
+		if speed == 0 {
+			speed = res.devices
+		}
+		if speed <= 0 {
+			continue
+		}
+		// Profiles hold the speed of one device. Runs saved without the
+		// device list are counted by their per-device speed lines.
+		measured := res.count
+		if measured == 0 {
+			measured = p.Devices
+		}
+		if len(devices) == 0 {
+			p.Devices = max(p.Devices, measured)
+		}
+		speed /= float64(measured)
+
+		iterations := res.iterations
+		if iterations == 0 {
+			iterations = hashcatBenchmarkIterations[mode]
+		}
+		for _, hashName := range names {
+			p.Speeds[hashName] = roundSpeed(normaliseHashcatSpeed(hashName, speed, iterations))
+		}
+	}
+
+	if len(p.Speeds) == 0 {
+		return nil, fmt.Errorf("no supported hash modes found in hashcat output")
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// normaliseHashcatSpeed rescales a speed measured by hashcat to the work
+// one hash takes with the default cost parameters
+func normaliseHashcatSpeed(hashName string, speed float64, iterations int) float64 {
+	benchmark := hash.DefaultParams()
+
+	switch hashName {
+	case "md5crypt", "sha256crypt", "sha512crypt":
+		return speed * float64(iterations) / float64(cryptDefaultRounds[hashName])
+	case "bcrypt":
+		benchmark.BcryptCost = int(math.Round(math.Log2(float64(iterations))))
+	case "PBKDF2-HMAC-SHA256", "PBKDF2-HMAC-SHA512":
+		// hashcat counts the iterations after the first
+		benchmark.PBKDF2Iterations = iterations + 1
+	case "scrypt":
+		benchmark.ScryptN = hashcatScryptParams.ScryptN
+		benchmark.ScryptR = hashcatScryptParams.ScryptR
+		benchmark.ScryptP = hashcatScryptParams.ScryptP
+	case "Argon2i", "Argon2id":
+		benchmark.Argon2Time = hashcatArgon2Params.Argon2Time
+		benchmark.Argon2Memory = hashcatArgon2Params.Argon2Memory
+		benchmark.Argon2Threads = hashcatArgon2Params.Argon2Threads
+	}
+
+	return speed * benchmark.CostFactor(hashName)
+}
+
+// roundSpeed drops the false precision left over from rescaling
+func roundSpeed(speed float64) float64 {
+	if speed >= 100 {
+		return math.Round(speed)
+	}
+	return math.Round(speed*100) / 100
+}
@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+)
+
+// checkSpeeds compares a profile's speeds with the expected ones, which must
+// be all of them
+func checkSpeeds(t *testing.T, p *Profile, want map[string]float64) {
+	t.Helper()
+	for name, speed := range want {
+		if got, ok := p.Speeds[name]; !ok {
+			t.Errorf("no speed for %s", name)
+		} else if got != speed {
+			t.Errorf("%s speed = %v, want %v", name, got, speed)
+		}
+	}
+	for name := range p.Speeds {
+		if _, ok := want[name]; !ok {
+			t.Errorf("unexpected speed for %s", name)
+		}
+	}
+}
+
+func TestLoadHashcatExample(t *testing.T) {
+	p, err := LoadHashcat("../examples/hashcat-benchmark.txt", "rtx4090")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "rtx4090" || p.Devices != 1 {
+		t.Errorf("got name %q and %d devices, want rtx4090 and 1", p.Name, p.Devices)
+	}
+	if !strings.HasSuffix(p.Description, " on NVIDIA GeForce RTX 4090") {
+		t.Errorf("description %q does not name the device", p.Description)
+	}
+
+	// Mode 22000 has no equivalent and is skipped
+	checkSpeeds(t, p, map[string]float64{
+		"MD5":      164.1e9,
+		"SHA-1":    50638.7e6,
+		"SHA-256":  21975.5e6,
+		"SHA-512":  7483.4e6,
+		"SHA3-256": 4523.8e6,
+		"NTLM":     288.5e9,
+		// The crypt schemes are benchmarked at their default rounds
+		"md5crypt":    68285.6e3,
+		"sha256crypt": 2769.3e3,
+		"sha512crypt": 1194.4e3,
+		// 32 iterations is cost 5, 32 times cheaper than the default cost 10
+		"bcrypt": 5750,
+		// N=16384 against the default N=32768
+		"scrypt": 3563,
+		// 1,000 iterations against the default 600,000 and 210,000
+		"PBKDF2-HMAC-SHA256": 14775,
+		"PBKDF2-HMAC-SHA512": 15456,
+	})
+}
+
+func TestParseHashcatMachineReadable(t *testing.T) {
+	const output = `1:0:1691:1740:57.07:100000000000
+2:0:1691:1740:57.07:60000000000
+1:1800:1691:1740:61.54:1000000
+2:1800:1691:1740:61.54:1000000
+1:3200:1691:1740:43.91:184000
+2:3200:1691:1740:43.91:16000
+1:22000:1691:1740:53.92:2533100
+`
+	p, err := ParseHashcat(strings.NewReader(output), "pair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Devices != 2 {
+		t.Errorf("Devices = %d, want 2", p.Devices)
+	}
+	// Speeds are per device, and machine-readable output has no iteration
+	// counts, so hashcat's benchmark defaults apply
+	checkSpeeds(t, p, map[string]float64{
+		"MD5":         80e9,
+		"sha512crypt": 1e6,
+		"bcrypt":      3125,
+	})
+}
+
+func TestParseHashcatIterations(t *testing.T) {
+	const output = `* Hash-Mode 3200 (bcrypt $2*$, Blowfish (Unix)) [Iterations: 1024]
+Speed.#1.........:     5000 H/s (43.91ms)
+* Hash-Mode 1800 (sha512crypt $6$, SHA512 (Unix)) [Iterations: 10000]
+Speed.#1.........:  1000.0 kH/s (61.54ms)
+* Hash-Mode 10900 (PBKDF2-HMAC-SHA256) [Iterations: 599999]
+Speed.#1.........:  2000 H/s (49.12ms)
+`
+	p, err := ParseHashcat(strings.NewReader(output), "custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSpeeds(t, p, map[string]float64{
+		// Cost 10 is the default
+		"bcrypt": 5000,
+		// Twice the default rounds, so twice as fast at the default
+		"sha512crypt":        2e6,
+		"PBKDF2-HMAC-SHA256": 2000,
+	})
+}
+
+func TestParseHashcatMultipleDevices(t *testing.T) {
+	const output = `* Device #1: NVIDIA GeForce RTX 4090, 23867/24563 MB, 128MCU
+* Device #2: NVIDIA GeForce RTX 4090, 23867/24563 MB, 128MCU
+* Hash-Mode 0 (MD5)
+Speed.#1.........:   164.1 GH/s (57.07ms)
+Speed.#2.........:   160.0 GH/s (57.07ms)
+Speed.#*.........:   324.0 GH/s
+* Hash-Mode 1000 (NTLM)
+Speed.#1.........:   288.5 GH/s (32.34ms)
+Speed.#2.........:   281.5 GH/s (32.34ms)
+`
+	p, err := ParseHashcat(strings.NewReader(output), "pair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Devices != 2 {
+		t.Errorf("Devices = %d, want 2", p.Devices)
+	}
+	// The "Speed.#*" total is preferred over the sum of the devices, and
+	// both are divided between them
+	checkSpeeds(t, p, map[string]float64{
+		"MD5":  162e9,
+		"NTLM": 285e9,
+	})
+	if speed, _ := p.Speed("MD5"); speed != 324e9 {
+		t.Errorf("combined MD5 speed = %v, want 324e9", speed)
+	}
+}
+
+func TestParseHashcatNoSupportedModes(t *testing.T) {
+	const output = "* Hash-Mode 22000 (WPA-PBKDF2-PMKID+EAPOL) [Iterations: 4095]\nSpeed.#1.........:  2533.1 kH/s\n"
+	if _, err := ParseHashcat(strings.NewReader(output), "wpa"); err == nil {
+		t.Error("ParseHashcat succeeded without a supported mode")
+	}
+}
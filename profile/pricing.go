@@ -0,0 +1,94 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Instance is a machine type rented from a cloud provider to crack passwords
+type Instance struct {
+	Name     string `json:"name" yaml:"name"`
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	// GPUs is the number of GPUs in one instance
+	GPUs int `json:"gpus" yaml:"gpus"`
+	// PricePerGPUHour is the price in dollars of one GPU for one hour
+	PricePerGPUHour float64 `json:"price_per_gpu_hour" yaml:"price_per_gpu_hour"`
+	// Profile names the attacker profile whose per-device speeds match one
+	// of the instance's GPUs; when empty the selected profile is used
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+}
+
+// Cost is the price and time of making a number of guesses on rented instances
+type Cost struct {
+	Dollars *big.Float
+	// GPUHours is the GPU time the guesses take
+	GPUHours *big.Float
+	// Seconds is how long one instance takes, with all its GPUs cracking in parallel
+	Seconds *big.Float
+}
+
+// Cost returns what the given number of guesses costs at a speed of
+// deviceSpeed hashes per second for each GPU. Renting more instances makes
+// the attack faster but not cheaper.
+func (i *Instance) Cost(guesses *big.Float, deviceSpeed float64) Cost {
+	gpuSeconds := new(big.Float).Quo(guesses, big.NewFloat(deviceSpeed))
+	gpuHours := new(big.Float).Quo(gpuSeconds, big.NewFloat(3600))
+	return Cost{
+		Dollars:  new(big.Float).Mul(gpuHours, big.NewFloat(i.PricePerGPUHour)),
+		GPUHours: gpuHours,
+		Seconds:  gpuSeconds.Quo(gpuSeconds, big.NewFloat(float64(i.GPUs))),
+	}
+}
+
+// validate checks an instance read from a pricing file
+func (i *Instance) validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return errors.New("instance without a name")
+	}
+	if i.GPUs < 1 {
+		return fmt.Errorf("instance %q: gpus must be at least 1", i.Name)
+	}
+	if i.PricePerGPUHour <= 0 {
+		return fmt.Errorf("instance %q: price_per_gpu_hour must be positive", i.Name)
+	}
+	return nil
+}
+
+// LoadPricing reads the instances listed under "instances" in a YAML or JSON
+// pricing file. JSON is chosen by the ".json" extension; anything else is
+// parsed as YAML.
+func LoadPricing(path string) ([]*Instance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f struct {
+		Instances []*Instance `json:"instances" yaml:"instances"`
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading pricing from %s: %w", path, err)
+	}
+	if len(f.Instances) == 0 {
+		return nil, fmt.Errorf("no instances found in %s", path)
+	}
+
+	for _, i := range f.Instances {
+		if err := i.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return f.Instances, nil
+}
@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstanceCost(t *testing.T) {
+	instance := &Instance{Name: "p5.48xlarge", GPUs: 8, PricePerGPUHour: 12.5}
+	tests := []struct {
+		name     string
+		guesses  float64
+		dollars  float64
+		gpuHours float64
+		seconds  float64
+	}{
+		// 3.6e14 guesses at 1e10 per GPU is 10 GPU-hours, or 4,500 seconds on 8 GPUs
+		{"exhaust", 3.6e14, 125, 10, 4500},
+		// Half the guesses cost half as much and take half as long
+		{"median", 1.8e14, 62.5, 5, 2250},
+		{"none", 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		c := instance.Cost(big.NewFloat(tt.guesses), 1e10)
+		dollars, _ := c.Dollars.Float64()
+		gpuHours, _ := c.GPUHours.Float64()
+		seconds, _ := c.Seconds.Float64()
+		if dollars != tt.dollars || gpuHours != tt.gpuHours || seconds != tt.seconds {
+			t.Errorf("%s: got $%v, %v GPU-hours and %v seconds, want $%v, %v and %v",
+				tt.name, dollars, gpuHours, seconds, tt.dollars, tt.gpuHours, tt.seconds)
+		}
+	}
+
+	// More GPUs per instance finish sooner at the same price
+	double := &Instance{Name: "double", GPUs: 16, PricePerGPUHour: 12.5}
+	c := double.Cost(big.NewFloat(3.6e14), 1e10)
+	if dollars, _ := c.Dollars.Float64(); dollars != 125 {
+		t.Errorf("16 GPUs cost $%v, want $125", dollars)
+	}
+	if seconds, _ := c.Seconds.Float64(); seconds != 2250 {
+		t.Errorf("16 GPUs take %v seconds, want 2250", seconds)
+	}
+}
+
+func TestLoadPricingExample(t *testing.T) {
+	instances, err := LoadPricing("../examples/pricing.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := instances[0]
+	if first.Name != "p5.48xlarge" || first.Provider != "AWS" || first.GPUs != 8 || first.PricePerGPUHour != 12.29 || first.Profile != "High-end GPU" {
+		t.Errorf("first instance = %+v", *first)
+	}
+}
+
+func TestLoadPricingInvalid(t *testing.T) {
+	tests := map[string]string{
+		"empty.yaml":    "instances: []\n",
+		"nogpus.yaml":   "instances:\n  - name: box\n    price_per_gpu_hour: 1\n",
+		"noprice.json":  `{"instances": [{"name": "box", "gpus": 1}]}`,
+		"unnamed.yaml":  "instances:\n  - gpus: 1\n    price_per_gpu_hour: 1\n",
+		"malformed.yml": "instances: [\n",
+	}
+	dir := t.TempDir()
+	for name, data := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPricing(path); err == nil {
+			t.Errorf("%s: LoadPricing succeeded", name)
+		}
+	}
+}
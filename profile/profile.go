@@ -0,0 +1,205 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sharafdin/crackulator/hash"
+)
+
+// Profile describes an attacker's hardware by its hash speeds
+type Profile struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Devices is the number of identical devices cracking in parallel
+	Devices int `json:"devices,omitempty" yaml:"devices,omitempty"`
+	// Speeds holds hashes per second of a single device, keyed by hash.Types
+	// name and measured with the default cost parameters
+	Speeds map[string]float64 `json:"speeds" yaml:"speeds"`
+}
+
+// Speed returns the combined speed of every device for a hash algorithm
+func (p *Profile) Speed(hashType string) (float64, bool) {
+	speed, ok := p.Speeds[hashType]
+	if !ok {
+		return 0, false
+	}
+	return speed * float64(p.devices()), true
+}
+
+// devices returns the device count, treating an unset count as one device
+func (p *Profile) devices() int {
+	if p.Devices < 1 {
+		return 1
+	}
+	return p.Devices
+}
+
+// validate checks a profile read from a file
+func (p *Profile) validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("profile without a name")
+	}
+	if p.Devices < 0 {
+		return fmt.Errorf("profile %q: devices must not be negative", p.Name)
+	}
+	if len(p.Speeds) == 0 {
+		return fmt.Errorf("profile %q: no speeds given", p.Name)
+	}
+	for name, speed := range p.Speeds {
+		if _, ok := hash.Types[name]; !ok {
+			return fmt.Errorf("profile %q: unknown hash algorithm %q (available: %s)", p.Name, name, strings.Join(hash.GetHashOptions(), ", "))
+		}
+		if speed <= 0 {
+			return fmt.Errorf("profile %q: speed for %s must be positive", p.Name, name)
+		}
+	}
+	return nil
+}
+
+// Set is an ordered collection of profiles with unique names
+type Set struct {
+	profiles []*Profile
+}
+
+// NewSet returns a set holding the built-in profiles
+func NewSet() *Set {
+	return &Set{profiles: append([]*Profile(nil), Builtin...)}
+}
+
+// Add inserts a profile, replacing any profile with the same name
+func (s *Set) Add(p *Profile) {
+	for i, existing := range s.profiles {
+		if existing.Name == p.Name {
+			s.profiles[i] = p
+			return
+		}
+	}
+	s.profiles = append(s.profiles, p)
+}
+
+// Find looks up a profile by name
+func (s *Set) Find(name string) (*Profile, bool) {
+	for _, p := range s.profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the profile names in the order they were added
+func (s *Set) Names() []string {
+	names := make([]string, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Profiles returns every profile in the set
+func (s *Set) Profiles() []*Profile {
+	return s.profiles
+}
+
+// LoadFile reads profiles from a YAML or JSON file and adds them to the set
+func (s *Set) LoadFile(path string) error {
+	profiles, err := Load(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		s.Add(p)
+	}
+	return nil
+}
+
+// file is the layout of a profile file: either a list under "profiles" or a single profile
+type file struct {
+	Profiles []*Profile `json:"profiles" yaml:"profiles"`
+	Profile  `yaml:",inline"`
+}
+
+// Load reads the profiles in a YAML or JSON file. JSON is chosen by the
+// ".json" extension; anything else is parsed as YAML.
+func Load(path string) ([]*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading profiles from %s: %w", path, err)
+	}
+
+	profiles := f.Profiles
+	if len(profiles) == 0 && f.Name != "" {
+		single := f.Profile
+		profiles = []*Profile{&single}
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles found in %s", path)
+	}
+
+	for _, p := range profiles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return profiles, nil
+}
+
+// Write encodes profiles as a YAML profile file
+func Write(w io.Writer, profiles []*Profile) error {
+	// Speeds are written in plain notation rather than Go's exponent form
+	type writtenProfile struct {
+		Name        string                `yaml:"name"`
+		Description string                `yaml:"description,omitempty"`
+		Devices     int                   `yaml:"devices,omitempty"`
+		Speeds      map[string]plainFloat `yaml:"speeds"`
+	}
+	var out struct {
+		Profiles []writtenProfile `yaml:"profiles"`
+	}
+	for _, p := range profiles {
+		wp := writtenProfile{Name: p.Name, Description: p.Description, Devices: p.Devices, Speeds: map[string]plainFloat{}}
+		for name, speed := range p.Speeds {
+			wp.Speeds[name] = plainFloat(speed)
+		}
+		out.Profiles = append(out.Profiles, wp)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// plainFloat is a number encoded in YAML without an exponent
+type plainFloat float64
+
+// MarshalYAML implements yaml.Marshaler
+func (f plainFloat) MarshalYAML() (interface{}, error) {
+	tag := "!!float"
+	if f == plainFloat(math.Trunc(float64(f))) {
+		tag = "!!int"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: strconv.FormatFloat(float64(f), 'f', -1, 64)}, nil
+}
@@ -0,0 +1,211 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/sharafdin/crackulator/password"
+)
+
+// BatchReport collects the reports of many passwords plus aggregate statistics
+type BatchReport struct {
+	Algorithm string       `json:"algorithm"`
+	System    string       `json:"system"`
+	Results   []*Report    `json:"results"`
+	Summary   BatchSummary `json:"summary"`
+}
+
+// BatchSummary holds the aggregate statistics of a batch audit
+type BatchSummary struct {
+	Total           int            `json:"total"`
+	Strengths       map[string]int `json:"strength_distribution"`
+	Common          *CommonSummary `json:"common,omitempty"`
+	Breached        *CommonSummary `json:"breached,omitempty"`
+	Policy          *PolicySummary `json:"policy,omitempty"`
+	MedianCrackTime CrackTime      `json:"median_crack_time"`
+}
+
+// CommonSummary counts how many audited passwords were found in the common list or breach corpus
+type CommonSummary struct {
+	Found   int     `json:"found"`
+	Percent float64 `json:"percent"`
+}
+
+// PolicySummary counts how many audited passwords comply with the policy
+type PolicySummary struct {
+	Policy  string  `json:"policy"`
+	Passed  int     `json:"passed"`
+	Percent float64 `json:"percent"`
+}
+
+// NewBatchReport computes the aggregate statistics over the given reports
+func NewBatchReport(algorithm, system string, results []*Report) *BatchReport {
+	summary := BatchSummary{
+		Total:     len(results),
+		Strengths: map[string]int{},
+	}
+
+	seconds := make([]float64, 0, len(results))
+	for _, r := range results {
+		summary.Strengths[r.Strength]++
+		// A withheld time counts as zero, as a listed password falls as soon
+		// as the attacker reaches it in the list
+		seconds = append(seconds, r.Theoretical.Seconds)
+
+		if r.CommonCheck != nil {
+			if summary.Common == nil {
+				summary.Common = &CommonSummary{}
+			}
+			if r.CommonCheck.Found {
+				summary.Common.Found++
+			}
+		}
+
+		if r.BreachCheck != nil {
+			if summary.Breached == nil {
+				summary.Breached = &CommonSummary{}
+			}
+			if r.BreachCheck.Found {
+				summary.Breached.Found++
+			}
+		}
+
+		if r.Policy != nil {
+			if summary.Policy == nil {
+				summary.Policy = &PolicySummary{Policy: r.Policy.Policy}
+			}
+			if r.Policy.Passed {
+				summary.Policy.Passed++
+			}
+		}
+	}
+
+	if summary.Common != nil && summary.Total > 0 {
+		summary.Common.Percent = float64(summary.Common.Found) * 100 / float64(summary.Total)
+	}
+	if summary.Breached != nil && summary.Total > 0 {
+		summary.Breached.Percent = float64(summary.Breached.Found) * 100 / float64(summary.Total)
+	}
+	if summary.Policy != nil && summary.Total > 0 {
+		summary.Policy.Percent = float64(summary.Policy.Passed) * 100 / float64(summary.Total)
+	}
+
+	summary.MedianCrackTime = NewCrackTime(big.NewFloat(median(seconds)))
+
+	return &BatchReport{
+		Algorithm: algorithm,
+		System:    system,
+		Results:   results,
+		Summary:   summary,
+	}
+}
+
+// WriteBatchJSON writes the batch report as indented JSON
+func WriteBatchJSON(w io.Writer, b *BatchReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// WriteBatchText writes a per-password table followed by the aggregate statistics
+func WriteBatchText(w io.Writer, b *BatchReport) {
+	fmt.Fprintln(w, "=================================================================")
+	fmt.Fprintln(w, "                  🔒 PASSWORD AUDIT REPORT 🔒                    ")
+	fmt.Fprintln(w, "=================================================================")
+	fmt.Fprintf(w, "Algorithm: %s\n", b.Algorithm)
+	fmt.Fprintf(w, "System: %s\n\n", b.System)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "#\tPassword\tLength\tStrength\tCommon\tBreaches\tPolicy\tCrack time")
+	for i, r := range b.Results {
+		common := "-"
+		if r.CommonCheck != nil {
+			common = formatBool(r.CommonCheck.Found)
+			if r.CommonCheck.Guesses > 0 {
+				common += fmt.Sprintf(" (#%d)", r.CommonCheck.Guesses)
+			}
+		}
+		breaches := "-"
+		if r.BreachCheck != nil && r.BreachCheck.Error == "" {
+			breaches = fmt.Sprint(r.BreachCheck.Count)
+		}
+		policy := "-"
+		if r.Policy != nil {
+			policy = "Pass"
+			if !r.Policy.Passed {
+				policy = "Fail"
+			}
+		}
+		fmt.Fprintf(table, "%d\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", i+1, r.Password, r.Length, r.Strength, common, breaches, policy,
+			formatCrackTime(r.Theoretical))
+	}
+	table.Flush()
+
+	fmt.Fprintln(w, "\n📊 SUMMARY:")
+	fmt.Fprintf(w, "Passwords audited: %d\n", b.Summary.Total)
+
+	fmt.Fprintln(w, "Strength distribution:")
+	for _, rating := range strengthOrder(b.Summary.Strengths) {
+		count := b.Summary.Strengths[rating]
+		fmt.Fprintf(w, "  %-12s %d (%.1f%%)\n", rating+":", count, float64(count)*100/float64(b.Summary.Total))
+	}
+
+	if b.Summary.Common != nil {
+		fmt.Fprintf(w, "Found in common password list: %d (%.1f%%)\n", b.Summary.Common.Found, b.Summary.Common.Percent)
+	}
+	if b.Summary.Breached != nil {
+		fmt.Fprintf(w, "Found in data breaches: %d (%.1f%%)\n", b.Summary.Breached.Found, b.Summary.Breached.Percent)
+	}
+	if b.Summary.Policy != nil {
+		fmt.Fprintf(w, "Comply with %s: %d (%.1f%%)\n", b.Summary.Policy.Policy, b.Summary.Policy.Passed, b.Summary.Policy.Percent)
+	}
+
+	fmt.Fprintf(w, "Median crack time: %s\n", formatCrackTime(b.Summary.MedianCrackTime))
+}
+
+// strengthOrder lists the known ratings from weakest to strongest, followed by any others
+func strengthOrder(counts map[string]int) []string {
+	var order []string
+	for _, rating := range password.StrengthRatings {
+		if counts[rating] > 0 {
+			order = append(order, rating)
+		}
+	}
+
+	var others []string
+	for rating := range counts {
+		known := false
+		for _, r := range password.StrengthRatings {
+			if r == rating {
+				known = true
+				break
+			}
+		}
+		if !known {
+			others = append(others, rating)
+		}
+	}
+	sort.Strings(others)
+
+	return append(order, others...)
+}
+
+// median returns the median of the values, or zero for an empty slice
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return sorted[middle-1]/2 + sorted[middle]/2
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// quoted matches the Go-quoted strings that descriptions and suggestions use
+// for fragments of the password
+var quoted = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// rank matches the dictionary rank in a pattern description, which names
+// the word as surely as the word itself
+var rank = regexp.MustCompile(` \(rank \d+\)`)
+
+// guessNumber matches the guess number a suggestion gives for a rule match,
+// which is as telling as a rank
+var guessNumber = regexp.MustCompile(` at guess \d+`)
+
+// extraLength matches the number of characters the length advice asks for,
+// which is worked out from the guess count
+var extraLength = regexp.MustCompile(`^add \d+ more`)
+
+// guessesDetail matches the guess count a failed min_guesses rule quotes
+var guessesDetail = regexp.MustCompile(` \(has 10\^[\d.]+\)`)
+
+// withheld stands in for a crack time that would give away a list rank
+var withheld = CrackTime{Withheld: true}
+
+// Redact returns a copy of the report that is safe for logs and screenshots.
+// The password is masked, every fragment of it quoted in patterns,
+// suggestions and policy details is replaced by asterisks, and the sample
+// hash, which would let anyone confirm a guess offline, is dropped, as are
+// the ranks, counts and guess numbers that look a word up in its list. For a
+// password found in a list, whose guess count is its rank, every time, price
+// and probability worked out from that count is withheld as well.
+func Redact(r *Report) *Report {
+	redacted := *r
+	redacted.Redacted = true
+	redacted.Password = MaskPassword(r.Password)
+	redacted.Hash.Sample = ""
+
+	redacted.Patterns = make([]Pattern, len(r.Patterns))
+	for i, p := range r.Patterns {
+		p.Token = maskAll(p.Token)
+		p.Description = rank.ReplaceAllString(maskQuoted(p.Description), "")
+		p.Guesses = 0
+		if p.Pattern == "date" {
+			p.Description = "date"
+		}
+		redacted.Patterns[i] = p
+	}
+
+	redacted.KeyboardWalks = make([]KeyboardWalk, len(r.KeyboardWalks))
+	for i, k := range r.KeyboardWalks {
+		k.Segment = maskAll(k.Segment)
+		k.Guesses = 0
+		redacted.KeyboardWalks[i] = k
+	}
+	if r.KeyboardWalks == nil {
+		redacted.KeyboardWalks = nil
+	}
+
+	listed := r.CommonCheck != nil && r.CommonCheck.Found
+	if r.CommonCheck != nil {
+		check := *r.CommonCheck
+		check.Base = maskAll(check.Base)
+		check.Rule = maskAll(check.Rule)
+		check.Rank, check.Count, check.Guesses = 0, 0, 0
+		redacted.CommonCheck = &check
+	}
+	if listed {
+		withholdEstimates(&redacted)
+	}
+
+	if r.Policy != nil {
+		policy := *r.Policy
+		policy.Rules = make([]PolicyRule, len(r.Policy.Rules))
+		for i, rule := range r.Policy.Rules {
+			rule.Detail = maskQuoted(rule.Detail)
+			if listed && rule.Rule == "min_guesses" {
+				rule.Detail = ""
+			}
+			policy.Rules[i] = rule
+		}
+		redacted.Policy = &policy
+	}
+
+	redacted.Suggestions = nil
+	for _, s := range r.Suggestions {
+		s = guessNumber.ReplaceAllString(maskQuoted(s), "")
+		if listed {
+			s = extraLength.ReplaceAllString(s, "add more")
+			s = guessesDetail.ReplaceAllString(s, "")
+		}
+		redacted.Suggestions = append(redacted.Suggestions, s)
+	}
+
+	return &redacted
+}
+
+// withholdEstimates drops everything the report works out from the guess
+// count: the count itself, the crack times, the percentiles, the budget
+// probability, the costs and the what-if comparison. Only the coarse
+// interpretation remains.
+func withholdEstimates(r *Report) {
+	r.Guesses, r.GuessesLog10 = nil, 0
+	r.GuessOrder = ""
+	r.Theoretical, r.Expected = withheld, withheld
+	if r.Benchmarked != nil {
+		benchmarked := withheld
+		r.Benchmarked = &benchmarked
+	}
+	r.Percentiles, r.Budget, r.Costs, r.WhatIf = nil, nil, nil, nil
+
+	scenarios := make([]Scenario, len(r.Scenarios))
+	for i, s := range r.Scenarios {
+		s.CrackTime = withheld
+		s.LockedOut, s.Precomputed = false, false
+		scenarios[i] = s
+	}
+	if r.Scenarios != nil {
+		r.Scenarios = scenarios
+	}
+}
+
+// MaskPassword shows only the first and last character of a password, with
+// an asterisk for each character in between. Passwords of up to three
+// characters are masked completely.
+func MaskPassword(password string) string {
+	runes := []rune(password)
+	if len(runes) <= 3 {
+		return maskAll(password)
+	}
+	return string(runes[0]) + strings.Repeat("*", len(runes)-2) + string(runes[len(runes)-1])
+}
+
+// maskAll replaces every character with an asterisk
+func maskAll(s string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(s))
+}
+
+// maskQuoted masks the contents of every quoted string in s
+func maskQuoted(s string) string {
+	return quoted.ReplaceAllStringFunc(s, func(q string) string {
+		unquoted, err := strconv.Unquote(q)
+		if err != nil {
+			unquoted = q[1 : len(q)-1]
+		}
+		return strconv.Quote(maskAll(unquoted))
+	})
+}
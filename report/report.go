@@ -0,0 +1,306 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/sharafdin/crackulator/password"
+	"github.com/sharafdin/crackulator/profile"
+)
+
+// Report collects everything computed while analyzing a password
+type Report struct {
+	Password       string         `json:"password"`
+	Redacted       bool           `json:"redacted,omitempty"`
+	Length         int            `json:"length"`
+	Runes          int            `json:"runes"`
+	Bytes          int            `json:"bytes"`
+	Composition    Composition    `json:"composition"`
+	Scripts        map[string]int `json:"scripts"`
+	Categories     map[string]int `json:"categories"`
+	Charsets       []Charset      `json:"charsets"`
+	CharsetSize    int            `json:"charset_size"`
+	Combinations   *big.Int       `json:"combinations"`
+	Strength       string         `json:"strength"`
+	Guesses        *big.Int       `json:"guesses"`
+	GuessesLog10   float64        `json:"guesses_log10"`
+	Patterns       []Pattern      `json:"patterns"`
+	KeyboardWalks  []KeyboardWalk `json:"keyboard_walks,omitempty"`
+	CommonCheck    *CommonCheck   `json:"common_check,omitempty"`
+	BreachCheck    *BreachCheck   `json:"breach_check,omitempty"`
+	Policy         *PolicyCheck   `json:"policy,omitempty"`
+	Hash           HashInfo       `json:"hash"`
+	Theoretical    CrackTime      `json:"theoretical_crack_time"`
+	GuessOrder     string         `json:"guess_order"` // "rank" or "uniform"
+	Expected       CrackTime      `json:"expected_crack_time"`
+	Percentiles    []Percentile   `json:"percentiles,omitempty"`
+	Budget         *Budget        `json:"budget,omitempty"`
+	Benchmarked    *CrackTime     `json:"benchmarked_crack_time,omitempty"`
+	BruteForce     CrackTime      `json:"brute_force_crack_time"`
+	Scenarios      []Scenario     `json:"scenarios,omitempty"`
+	Costs          []CrackCost    `json:"costs,omitempty"`
+	Interpretation string         `json:"interpretation"`
+	Suggestions    []string       `json:"suggestions,omitempty"`
+	WhatIf         *WhatIf        `json:"what_if,omitempty"`
+}
+
+// WhatIf shows how the theoretical crack time grows if random characters
+// were added to the password
+type WhatIf struct {
+	ExtraCharacters int       `json:"extra_characters"`
+	Guesses         *big.Int  `json:"guesses"`
+	CrackTime       CrackTime `json:"crack_time"`
+}
+
+// Percentile is the time after which the password is cracked with the given probability
+type Percentile struct {
+	Percent   float64   `json:"percent"`
+	CrackTime CrackTime `json:"crack_time"`
+}
+
+// Budget is the chance of the password being cracked within a time budget
+type Budget struct {
+	Duration    string  `json:"duration"`
+	Seconds     float64 `json:"seconds"`
+	Devices     int     `json:"devices"`
+	Probability float64 `json:"probability"`
+}
+
+// CrackCost is what cracking the password costs on one type of rented instance
+type CrackCost struct {
+	Instance        string  `json:"instance"`
+	Provider        string  `json:"provider,omitempty"`
+	GPUs            int     `json:"gpus"`
+	PricePerGPUHour float64 `json:"price_per_gpu_hour"`
+	Profile         string  `json:"profile"`
+	// Exhaust pays for every guess the password could need, Median for a 50% chance
+	Exhaust Price `json:"exhaust"`
+	Median  Price `json:"median"`
+}
+
+// Price is the cost of a number of guesses and the time one instance takes to make them
+type Price struct {
+	Dollars  float64   `json:"dollars"`
+	GPUHours float64   `json:"gpu_hours"`
+	Time     CrackTime `json:"time"`
+}
+
+// NewPrice converts a cost into a report entry
+func NewPrice(c profile.Cost) Price {
+	return Price{Dollars: clampFloat(c.Dollars), GPUHours: clampFloat(c.GPUHours), Time: NewCrackTime(c.Seconds)}
+}
+
+// Scenario is the crack time against one kind of attacker
+type Scenario struct {
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	GuessesPerSecond float64   `json:"guesses_per_second"`
+	CrackTime        CrackTime `json:"crack_time"`
+	// LockedOut is set when the account locks after Lockout failures, before
+	// the attacker reaches the password
+	LockedOut bool  `json:"locked_out,omitempty"`
+	Lockout   int64 `json:"lockout,omitempty"`
+	// Precomputed is set when lookup tables already hold the password
+	Precomputed bool `json:"precomputed,omitempty"`
+}
+
+// NewScenarios estimates the crack time of the given number of guesses in each scenario
+func NewScenarios(scenarios []password.Scenario, guesses *big.Int) []Scenario {
+	result := make([]Scenario, 0, len(scenarios))
+	for _, s := range scenarios {
+		estimate := s.Estimate(guesses)
+		result = append(result, Scenario{
+			Name:             s.Name,
+			Description:      s.Description,
+			GuessesPerSecond: s.GuessesPerSecond,
+			CrackTime:        NewCrackTime(estimate.Seconds),
+			LockedOut:        estimate.LockedOut,
+			Lockout:          s.Lockout,
+			Precomputed:      estimate.Precomputed,
+		})
+	}
+	return result
+}
+
+// Pattern is one part of the cheapest decomposition of the password into guessable patterns
+type Pattern struct {
+	Pattern     string  `json:"pattern"`
+	Token       string  `json:"token"`
+	Guesses     float64 `json:"guesses"`
+	Description string  `json:"description"`
+}
+
+// Composition records which character classes appear in the password
+type Composition struct {
+	Lower    bool `json:"lower"`
+	Upper    bool `json:"upper"`
+	Caseless bool `json:"caseless"`
+	Digit    bool `json:"digit"`
+	Special  bool `json:"special"`
+	Emoji    bool `json:"emoji"`
+}
+
+// Charset is one group of characters counted towards the character set size
+type Charset struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// NewCharsets converts the analysis character sets into report entries
+func NewCharsets(charsets []password.Charset) []Charset {
+	result := make([]Charset, 0, len(charsets))
+	for _, c := range charsets {
+		result = append(result, Charset{Name: c.Name, Size: c.Size})
+	}
+	return result
+}
+
+// CommonCheck holds the result of a common password list lookup
+type CommonCheck struct {
+	Lists []string `json:"lists"` // every list checked
+	Found bool     `json:"found"`
+	List  string   `json:"list,omitempty"`  // list the password was found in
+	Rank  int64    `json:"rank,omitempty"`  // line of the password in that list
+	Count int64    `json:"count,omitempty"` // frequency from a "password:count" list
+	// Guesses is the attacker's guess number for the password
+	Guesses int64 `json:"guesses,omitempty"`
+	// Base and Rule are set when a mangling rule derives the password from a list entry
+	Base string `json:"base,omitempty"`
+	Rule string `json:"rule,omitempty"`
+}
+
+// BreachCheck holds the result of a breached-password range lookup
+type BreachCheck struct {
+	Source string `json:"source"`
+	Found  bool   `json:"found"`
+	Count  int64  `json:"count"`           // times the password appears in breaches
+	Error  string `json:"error,omitempty"` // set when the lookup failed
+}
+
+// PolicyCheck holds the outcome of checking the password against a policy
+type PolicyCheck struct {
+	Policy string       `json:"policy"`
+	Passed bool         `json:"passed"`
+	Rules  []PolicyRule `json:"rules"`
+}
+
+// PolicyRule is the outcome of one policy rule
+type PolicyRule struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	Passed      bool   `json:"passed"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// NewPolicyCheck converts a policy result into its report entry
+func NewPolicyCheck(result password.PolicyResult) *PolicyCheck {
+	check := &PolicyCheck{Policy: result.Policy, Passed: result.Passed, Rules: make([]PolicyRule, 0, len(result.Rules))}
+	for _, r := range result.Rules {
+		check.Rules = append(check.Rules, PolicyRule{Rule: r.Rule, Description: r.Description, Passed: r.Passed, Detail: r.Detail})
+	}
+	return check
+}
+
+// HashInfo describes the simulated hash algorithm and attacker system
+type HashInfo struct {
+	Algorithm        string          `json:"algorithm"`
+	System           string          `json:"system"`
+	Parameters       string          `json:"parameters,omitempty"`
+	TheoreticalSpeed float64         `json:"theoretical_speed"`
+	BenchmarkedSpeed float64         `json:"benchmarked_speed,omitempty"`
+	Benchmark        *BenchmarkStats `json:"benchmark,omitempty"`
+	Sample           string          `json:"sample,omitempty"`
+}
+
+// BenchmarkStats describes how the benchmarked speed was measured
+type BenchmarkStats struct {
+	Workers    int       `json:"workers"`
+	PerCore    float64   `json:"per_core_speed"`
+	Rounds     []float64 `json:"rounds"`
+	Min        float64   `json:"min"`
+	Median     float64   `json:"median"`
+	StdDev     float64   `json:"stddev"`
+	MeasuredAt time.Time `json:"measured_at"`
+	Cached     bool      `json:"cached"` // reused from an earlier run
+}
+
+// CrackTime is a cracking time estimate in both raw seconds and a readable unit
+type CrackTime struct {
+	Seconds float64 `json:"seconds"`
+	Value   string  `json:"value"`
+	Unit    string  `json:"unit"`
+	// Withheld is set, and the time left empty, when a redacted report
+	// hides it
+	Withheld bool `json:"withheld,omitempty"`
+}
+
+// KeyboardWalk is a run of adjacent keys found on one keyboard layout
+type KeyboardWalk struct {
+	Layout  string  `json:"layout"`
+	Segment string  `json:"segment"`
+	Start   int     `json:"start"`
+	Turns   int     `json:"turns"`
+	Shifted int     `json:"shifted"`
+	Guesses float64 `json:"guesses"`
+}
+
+// NewKeyboardWalks converts the estimator's spatial matches into report entries
+func NewKeyboardWalks(walks []*password.Match) []KeyboardWalk {
+	var result []KeyboardWalk
+	for _, m := range walks {
+		result = append(result, KeyboardWalk{
+			Layout:  m.Graph,
+			Segment: m.Token,
+			Start:   m.I,
+			Turns:   m.Turns,
+			Shifted: m.ShiftedCount,
+			Guesses: m.Guesses,
+		})
+	}
+	return result
+}
+
+// NewPatterns converts the estimator's match sequence into report patterns
+func NewPatterns(sequence []*password.Match) []Pattern {
+	patterns := make([]Pattern, 0, len(sequence))
+	for _, m := range sequence {
+		patterns = append(patterns, Pattern{
+			Pattern:     m.Pattern,
+			Token:       m.Token,
+			Guesses:     m.Guesses,
+			Description: m.Describe(),
+		})
+	}
+	return patterns
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, r *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// clampFloat converts to a float64, clamping astronomically large values
+// since JSON cannot represent infinity
+func clampFloat(f *big.Float) float64 {
+	raw, _ := f.Float64()
+	if math.IsInf(raw, 1) {
+		raw = math.MaxFloat64
+	}
+	return raw
+}
+
+// NewCrackTime builds a CrackTime from a number of seconds
+func NewCrackTime(seconds *big.Float) CrackTime {
+	value, unit, _ := password.FormatTime(seconds)
+
+	return CrackTime{
+		Seconds: clampFloat(seconds),
+		Value:   value,
+		Unit:    unit,
+	}
+}
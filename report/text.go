@@ -0,0 +1,418 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes the report in the human-readable terminal format
+func WriteText(w io.Writer, r *Report) {
+	// Print header
+	fmt.Fprintln(w, "=================================================================")
+	fmt.Fprintln(w, "                  🔒 PASSWORD ANALYSIS REPORT 🔒                  ")
+	fmt.Fprintln(w, "=================================================================")
+
+	// Print password summary
+	fmt.Fprintln(w, "\n📋 PASSWORD SUMMARY:")
+	if r.Redacted {
+		fmt.Fprintf(w, "Password: %s (masked; use --show-password to display it)\n", r.Password)
+	} else {
+		fmt.Fprintf(w, "Password: %s\n", r.Password)
+	}
+	if r.Runes != r.Length || r.Bytes != r.Length {
+		fmt.Fprintf(w, "Length: %d characters (%d code points, %d bytes)\n", r.Length, r.Runes, r.Bytes)
+	} else {
+		fmt.Fprintf(w, "Length: %d characters\n", r.Length)
+	}
+
+	// Print character types
+	fmt.Fprintln(w, "\n🔤 CHARACTER COMPOSITION:")
+	fmt.Fprintf(w, "Lowercase letters: %s\n", formatBool(r.Composition.Lower))
+	fmt.Fprintf(w, "Uppercase letters: %s\n", formatBool(r.Composition.Upper))
+	fmt.Fprintf(w, "Letters without case (e.g. Arabic, CJK): %s\n", formatBool(r.Composition.Caseless))
+	fmt.Fprintf(w, "Digits: %s\n", formatBool(r.Composition.Digit))
+	fmt.Fprintf(w, "Special characters: %s\n", formatBool(r.Composition.Special))
+	fmt.Fprintf(w, "Emoji: %s\n", formatBool(r.Composition.Emoji))
+	fmt.Fprintf(w, "Scripts: %s\n", formatCounts(r.Scripts))
+	fmt.Fprintf(w, "Character set size: %d\n", r.CharsetSize)
+	for _, c := range r.Charsets {
+		fmt.Fprintf(w, "  - %s: %d\n", c.Name, c.Size)
+	}
+
+	// Print strength rating
+	fmt.Fprintln(w, "\n💪 STRENGTH ASSESSMENT:")
+	fmt.Fprintf(w, "Basic strength rating: %s\n", r.Strength)
+
+	// Print the patterns an attacker would exploit
+	fmt.Fprintln(w, "\n🧩 PATTERN ANALYSIS:")
+	if r.Guesses != nil {
+		fmt.Fprintf(w, "Estimated guesses: %s (10^%.2f)\n", formatBigInt(r.Guesses), r.GuessesLog10)
+	} else {
+		fmt.Fprintln(w, "Estimated guesses: withheld, as the password is in a common list")
+	}
+	for _, p := range r.Patterns {
+		fmt.Fprintf(w, "  - %q: %s\n", p.Token, p.Description)
+	}
+
+	// Print keyboard walks found on any layout
+	if len(r.KeyboardWalks) > 0 {
+		fmt.Fprintln(w, "\n⌨️  KEYBOARD WALKS:")
+		for _, k := range r.KeyboardWalks {
+			fmt.Fprintf(w, "  - %q on %s (%d turn(s), %d shifted)\n", k.Segment, k.Layout, k.Turns, k.Shifted)
+		}
+	}
+
+	// Print common password check results
+	if r.CommonCheck != nil {
+		fmt.Fprintln(w, "\n🔍 COMMON PASSWORD CHECK:")
+		if r.CommonCheck.Found {
+			fmt.Fprintln(w, "⚠️  WARNING: This password appears in common password lists!")
+			if r.CommonCheck.Rule != "" && r.Redacted {
+				fmt.Fprintf(w, "    Derived from an entry of %s by a mangling rule.\n", r.CommonCheck.List)
+			} else if r.CommonCheck.Rule != "" {
+				fmt.Fprintf(w, "    Derived from %q (rank %d in %s) by the rule %q,\n", r.CommonCheck.Base, r.CommonCheck.Rank, r.CommonCheck.List, r.CommonCheck.Rule)
+				fmt.Fprintf(w, "    so a rule attack reaches it at guess %d.\n", r.CommonCheck.Guesses)
+			} else if r.CommonCheck.Rank > 0 {
+				fmt.Fprintf(w, "    Found at rank %d in %s", r.CommonCheck.Rank, r.CommonCheck.List)
+				if r.CommonCheck.Count > 0 {
+					fmt.Fprintf(w, " (seen %d times)", r.CommonCheck.Count)
+				}
+				fmt.Fprintf(w, ", so it falls within %d guesses.\n", r.CommonCheck.Guesses)
+			} else {
+				fmt.Fprintf(w, "    Found in %s.\n", r.CommonCheck.List)
+			}
+			fmt.Fprintln(w, "    It is highly recommended to choose a different password.")
+		} else {
+			fmt.Fprintln(w, "✅  Good news! Your password was not found in the common password list.")
+		}
+	}
+
+	if r.BreachCheck != nil {
+		fmt.Fprintln(w, "\n🔓 BREACHED PASSWORD CHECK:")
+		switch {
+		case r.BreachCheck.Error != "":
+			fmt.Fprintf(w, "❓  Could not check %s: %s\n", r.BreachCheck.Source, r.BreachCheck.Error)
+		case r.BreachCheck.Found:
+			fmt.Fprintf(w, "⚠️  WARNING: This password has appeared %d times in data breaches!\n", r.BreachCheck.Count)
+			fmt.Fprintln(w, "    Attackers try breached passwords first; do not use it.")
+		default:
+			fmt.Fprintln(w, "✅  Good news! Your password was not found in any known data breach.")
+		}
+	}
+
+	if r.Policy != nil {
+		fmt.Fprintf(w, "\n📜 PASSWORD POLICY (%s):\n", r.Policy.Policy)
+		for _, rule := range r.Policy.Rules {
+			mark := "✅"
+			if !rule.Passed {
+				mark = "❌"
+			}
+			fmt.Fprintf(w, "%s  %s", mark, rule.Description)
+			if rule.Detail != "" {
+				fmt.Fprintf(w, " (%s)", rule.Detail)
+			}
+			fmt.Fprintln(w)
+		}
+		if r.Policy.Passed {
+			fmt.Fprintln(w, "The password complies with the policy.")
+		} else {
+			fmt.Fprintln(w, "The password does not comply with the policy.")
+		}
+	}
+
+	// Print cracking difficulty
+	fmt.Fprintln(w, "\n🔢 BRUTE FORCE COMPLEXITY:")
+	fmt.Fprintf(w, "Possible combinations: %s\n", formatBigInt(r.Combinations))
+
+	// Print hash information
+	fmt.Fprintln(w, "\n🔐 HASH INFORMATION:")
+	fmt.Fprintf(w, "Selected algorithm: %s\n", r.Hash.Algorithm)
+	if r.Hash.Parameters != "" {
+		fmt.Fprintf(w, "Parameters: %s\n", r.Hash.Parameters)
+	}
+	fmt.Fprintf(w, "Selected system: %s\n", r.Hash.System)
+	fmt.Fprintf(w, "Theoretical hash speed: %s hashes/second\n", formatSpeed(r.Hash.TheoreticalSpeed))
+
+	if r.Benchmarked != nil {
+		fmt.Fprintf(w, "Your computer's benchmark: %s hashes/second\n", formatSpeed(r.Hash.BenchmarkedSpeed))
+		if b := r.Hash.Benchmark; b != nil {
+			fmt.Fprintf(w, "  %d workers, %s hashes/second per core\n", b.Workers, formatSpeed(b.PerCore))
+			fmt.Fprintf(w, "  %d rounds: min %s, median %s, std dev %s\n", len(b.Rounds), formatSpeed(b.Min), formatSpeed(b.Median), formatSpeed(b.StdDev))
+			if b.Cached {
+				fmt.Fprintf(w, "  Cached result from %s (use --refresh-benchmark to measure again)\n", b.MeasuredAt.Local().Format("2006-01-02 15:04"))
+			}
+		}
+	}
+
+	if r.Hash.Sample != "" {
+		fmt.Fprintf(w, "Sample hash output: %s\n", r.Hash.Sample)
+	}
+
+	// Print cracking time estimation
+	fmt.Fprintln(w, "\n⏱️  CRACKING TIME ESTIMATION:")
+	fmt.Fprintf(w, "For %s (theoretical): %s\n", r.Hash.System, formatCrackTime(r.Theoretical))
+	switch {
+	case r.Theoretical.Withheld:
+		fmt.Fprintln(w, "  Crack times, chances and costs are withheld, as the password is in a common list and they would give away its rank.")
+	case r.GuessOrder == "uniform":
+		fmt.Fprintf(w, "  Expected on average: %s (half of the guesses, as any of them may be the password)\n", formatCrackTime(r.Expected))
+		for _, p := range r.Percentiles {
+			fmt.Fprintf(w, "  %g%% chance within: %s\n", p.Percent, formatCrackTime(p.CrackTime))
+		}
+	default:
+		fmt.Fprintln(w, "  The attacker reaches the password at a known guess, so this is also the expected time.")
+	}
+	if b := r.Budget; b != nil {
+		devices := ""
+		if b.Devices > 1 {
+			devices = fmt.Sprintf(" (%d devices)", b.Devices)
+		}
+		fmt.Fprintf(w, "  %s chance of being cracked within %s on %s%s\n", formatProbability(b.Probability), b.Duration, r.Hash.System, devices)
+	}
+
+	if r.Benchmarked != nil {
+		fmt.Fprintf(w, "For your computer (benchmarked): %s\n", formatCrackTime(*r.Benchmarked))
+	}
+
+	fmt.Fprintf(w, "Naive brute force on %s (charset^length): %s\n", r.Hash.System, formatCrackTime(r.BruteForce))
+
+	fmt.Fprintf(w, "Security assessment: %s\n", r.Interpretation)
+
+	if len(r.Scenarios) > 0 {
+		fmt.Fprintln(w, "\n🎯 ATTACK SCENARIOS:")
+		for _, s := range r.Scenarios {
+			fmt.Fprintf(w, "  - %s (%s guesses/second): ", s.Description, formatSpeed(s.GuessesPerSecond))
+			switch {
+			case s.LockedOut:
+				fmt.Fprintf(w, "never, the account locks after %d failed guesses\n", s.Lockout)
+			case s.Precomputed:
+				fmt.Fprintln(w, "instantly, the password is in the lookup tables")
+			default:
+				fmt.Fprintf(w, "%s\n", formatCrackTime(s.CrackTime))
+			}
+		}
+	}
+
+	if len(r.Costs) > 0 {
+		fmt.Fprintf(w, "\n💰 COST TO CRACK ON RENTED GPUS (%s):\n", r.Hash.Algorithm)
+		for _, c := range r.Costs {
+			fmt.Fprintf(w, "  - %s", c.Instance)
+			if c.Provider != "" {
+				fmt.Fprintf(w, " on %s", c.Provider)
+			}
+			fmt.Fprintf(w, " (%d GPUs at %s per GPU-hour, %s speeds):\n", c.GPUs, formatDollars(c.PricePerGPUHour), c.Profile)
+			if r.GuessOrder == "uniform" {
+				fmt.Fprintf(w, "      %s for a 50%% chance (%s on one instance)\n", formatDollars(c.Median.Dollars), formatCrackTime(c.Median.Time))
+				fmt.Fprintf(w, "      %s to try every guess (%s on one instance)\n", formatDollars(c.Exhaust.Dollars), formatCrackTime(c.Exhaust.Time))
+			} else {
+				fmt.Fprintf(w, "      %s to reach the password (%s on one instance)\n", formatDollars(c.Exhaust.Dollars), formatCrackTime(c.Exhaust.Time))
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\n💡 HOW TO IMPROVE:")
+	if len(r.Suggestions) == 0 {
+		fmt.Fprintln(w, "No obvious weaknesses found.")
+	}
+	for _, s := range r.Suggestions {
+		fmt.Fprintf(w, "  - %s\n", s)
+	}
+	if r.WhatIf != nil {
+		fmt.Fprintf(w, "With %d more random characters it would take %s instead of %s.\n", r.WhatIf.ExtraCharacters,
+			formatCrackTime(r.WhatIf.CrackTime), formatCrackTime(r.Theoretical))
+	}
+
+	fmt.Fprintln(w, "\n=================================================================")
+	fmt.Fprintln(w, "                       END OF REPORT                            ")
+	fmt.Fprintln(w, "=================================================================")
+}
+
+// dollarUnits are the suffixes used for large amounts of money
+var dollarUnits = []struct {
+	size   float64
+	suffix string
+}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}}
+
+// formatDollars writes an amount of money the way it is said, such as
+// "$2.30M", falling back to powers of ten for astronomical amounts
+func formatDollars(d float64) string {
+	switch {
+	case d < 0.01:
+		return "< $0.01"
+	case d >= 1e15:
+		exponent := math.Floor(math.Log10(d))
+		return fmt.Sprintf("$%.2f × 10^%d", d/math.Pow(10, exponent), int(exponent))
+	}
+	for _, u := range dollarUnits {
+		if d >= u.size {
+			return fmt.Sprintf("$%.2f%s", d/u.size, u.suffix)
+		}
+	}
+	return fmt.Sprintf("$%.2f", d)
+}
+
+// formatProbability writes a probability as a percentage, keeping small
+// chances visible instead of rounding them to zero
+func formatProbability(p float64) string {
+	switch {
+	case p == 0:
+		return "0%"
+	case p < 0.0001:
+		return "< 0.01%"
+	case p >= 1:
+		return "100%"
+	default:
+		return fmt.Sprintf("%.2f%%", p*100)
+	}
+}
+
+// formatCounts lists map entries as "name (count)" in alphabetical order
+func formatCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatBool returns "Yes" for true and "No" for false
+func formatBool(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+// formatInt64 formats large integers with commas or suffixes to make them more readable
+func formatInt64(n int64) string {
+	// For small numbers, just add commas
+	if n < 1000000 {
+		return formatWithCommas(n)
+	}
+
+	// For larger numbers, use appropriate suffixes
+	if n < 1000000000 {
+		// Million
+		return fmt.Sprintf("%.2f million", float64(n)/1000000)
+	} else {
+		// Billion
+		return fmt.Sprintf("%.2f billion", float64(n)/1000000000)
+	}
+}
+
+// formatSpeed formats a hash rate, keeping two decimals for rates too slow to round to whole hashes
+func formatSpeed(speed float64) string {
+	if speed < 100 {
+		return fmt.Sprintf("%.2f", speed)
+	}
+	return formatInt64(int64(speed))
+}
+
+// formatBigInt formats big integers to be more readable
+func formatBigInt(n *big.Int) string {
+	// Convert to string
+	str := n.String()
+
+	// If it's a huge number, use scientific notation
+	if len(str) > 15 {
+		// Convert to big float for scientific notation
+		bf := new(big.Float).SetInt(n)
+		// Find the order of magnitude (approximate)
+		magnitude := len(str) - 1
+		// Divide by 10^magnitude to get a number between 1 and 10
+		divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(magnitude)), nil))
+		result := new(big.Float).Quo(bf, divisor)
+
+		// Format as scientific notation
+		resultStr := fmt.Sprintf("%.2f × 10^%d", result, magnitude)
+		return resultStr
+	}
+
+	// For smaller big integers, just use commas
+	return addCommasToString(str)
+}
+
+// formatCrackTime writes a crack time with its unit, or "withheld" when a
+// redacted report hides it
+func formatCrackTime(t CrackTime) string {
+	if t.Withheld {
+		return "withheld"
+	}
+	return formatTimeString(t.Value) + " " + t.Unit
+}
+
+// formatTimeString makes time values more readable
+func formatTimeString(timeStr string) string {
+	// Try to convert to float
+	val, err := strconv.ParseFloat(timeStr, 64)
+	if err != nil {
+		// If not numeric, just return the original
+		return timeStr
+	}
+
+	// Values beyond int64 range would overflow, so fall back to scientific notation
+	if val >= 1e15 {
+		intVal, _ := big.NewFloat(val).Int(nil)
+		return formatBigInt(intVal)
+	}
+
+	// Format with commas and 2 decimal places if it's a large value
+	if val >= 1000 {
+		// Round to the nearest whole number for large values
+		intVal := int64(val)
+		return formatWithCommas(intVal)
+	}
+
+	// Pattern-based estimates are often far below the display precision
+	if val < 0.01 {
+		return "< 0.01"
+	}
+
+	// For smaller values, keep decimal places
+	return fmt.Sprintf("%.2f", val)
+}
+
+// formatWithCommas adds commas to int64 values
+func formatWithCommas(n int64) string {
+	return addCommasToString(strconv.FormatInt(n, 10))
+}
+
+// addCommasToString adds commas as thousands separators
+func addCommasToString(str string) string {
+	// Find the decimal point position if any
+	decimalPos := strings.Index(str, ".")
+
+	var intPart string
+	var decimalPart string
+
+	if decimalPos >= 0 {
+		intPart = str[:decimalPos]
+		decimalPart = str[decimalPos:]
+	} else {
+		intPart = str
+		decimalPart = ""
+	}
+
+	// Add commas to the integer part
+	var result []byte
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			result = append(result, ',')
+		}
+		result = append(result, byte(c))
+	}
+
+	// Add back the decimal part if any
+	return string(result) + decimalPart
+}
@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtin holds the rulesets available by name
+var builtin = map[string]string{
+	"best64": best64,
+	"basic":  basicRules(),
+}
+
+// BuiltinNames lists the built-in rulesets
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// best64 follows hashcat's best64.rule, a small set of the rules that crack
+// the most passwords, ordered by success
+const best64 = `## nothing, reverse, case... base stuff
+:
+r
+u
+T0
+## simple number append
+$0
+$1
+$2
+$3
+$4
+$5
+$6
+$7
+$8
+$9
+## special number append
+$0 $0
+$0 $1
+$0 $2
+$1 $1
+$1 $2
+$1 $3
+$2 $1
+$2 $2
+$2 $3
+$6 $9
+$7 $7
+$8 $8
+$9 $9
+$1 $2 $3
+## high frequency append
+$e
+$s
+## high frequency overwrite at end
+] $a
+] ] $s
+] ] $a
+] ] $e $r
+] ] $i $e
+] ] ] $o
+] ] ] $y
+] ] ] $1 $2 $3
+] ] ] $m $a $n
+] ] ] $d $o $g
+## high frequency prepend
+^1
+^e ^h ^t
+## high frequency overwrite at start
+o0d
+o0m o1a
+## leetify
+so0
+si1
+se3
+## simple delete
+D2
+D2 D2
+D3
+D4
+## undouble word
+'5 D3
+'5 $1
+## removes suffixes from 'strings' in 'password'
+]
+] ]
+] ] ]
+] ] ] d
+] ] D1 ]
+## rotates
++5 ] } } } } '4
+O02 { { { { { {
+} ] ] {
+} } -0 O12
+## unique word
+Y1
+Y2 '4
+`
+
+// basicRules combines the habits people use to dress up a dictionary word:
+// capitalisation, l33t substitutions and appended digits, years and
+// punctuation, plus whole-word reversal and duplication
+func basicRules() string {
+	cases := []string{"", "c", "u"}
+	leets := []string{"", "sa@", "so0", "se3", "si1", "ss$", "sa@ so0", "sa@ se3 si1 so0 ss$"}
+	suffixes := []string{"", "$1", "$!", "$1 $!", "$1 $2 $3", "$1 $2 $3 $!"}
+	var years, exclaimed []string
+	for year := 1970; year <= 2030; year++ {
+		appended := "$" + strings.Join(strings.Split(fmt.Sprint(year), ""), " $")
+		years = append(years, appended)
+		exclaimed = append(exclaimed, appended+" $!")
+	}
+	suffixes = append(append(suffixes, years...), exclaimed...)
+
+	var b strings.Builder
+	for _, suffix := range suffixes {
+		for _, leet := range leets {
+			for _, c := range cases {
+				rule := strings.Join(strings.Fields(c+" "+leet+" "+suffix), " ")
+				if rule == "" {
+					rule = ":"
+				}
+				b.WriteString(rule + "\n")
+			}
+		}
+	}
+	b.WriteString("r\nd\nc r\nc d\n")
+	return b.String()
+}
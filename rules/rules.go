@@ -0,0 +1,459 @@
+// Package rules implements the word mangling rules of hashcat and John the Ripper.
+package rules
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Rule is one line of a rule file: functions applied to a word from left to right
+type Rule struct {
+	Text string
+	ops  []op
+}
+
+// op is a single rule function with its position and character arguments
+type op struct {
+	fn   byte
+	args []byte
+}
+
+// Set is an ordered list of rules, in the order an attacker applies them
+type Set struct {
+	Name  string
+	Rules []Rule
+	// Skipped counts lines of the source that use unsupported functions
+	Skipped int
+}
+
+// Functions and the kinds of argument each takes: 'N' a position or count
+// (0-9, A-Z for 10-35), 'X' a character
+var signatures = map[byte]string{
+	':': "", 'l': "", 'u': "", 'c': "", 'C': "", 't': "", 'T': "N",
+	'r': "", 'd': "", 'p': "N", 'f': "", '{': "", '}': "",
+	'$': "X", '^': "X", '[': "", ']': "", 'D': "N", 'x': "NN",
+	'O': "NN", 'i': "NX", 'o': "NX", '\'': "N", 's': "XX", '@': "X",
+	'z': "N", 'Z': "N", 'q': "", 'k': "", 'K': "", '*': "NN",
+	'L': "N", 'R': "N", '+': "N", '-': "N", '.': "N", ',': "N",
+	'y': "N", 'Y': "N", 'E': "", 'e': "X",
+	// Rejection functions discard the candidate
+	'<': "N", '>': "N", '_': "N", '!': "X", '/': "X", '(': "X", ')': "X",
+	'=': "NX", '%': "NX",
+}
+
+// Parse compiles one rule. Spaces between functions are ignored.
+func Parse(text string) (Rule, error) {
+	rule := Rule{Text: text}
+	for i := 0; i < len(text); {
+		fn := text[i]
+		i++
+		if fn == ' ' || fn == '\t' {
+			continue
+		}
+
+		signature, ok := signatures[fn]
+		if !ok {
+			return Rule{}, fmt.Errorf("unsupported rule function %q in %q", fn, text)
+		}
+		if i+len(signature) > len(text) {
+			return Rule{}, fmt.Errorf("missing argument to %q in %q", fn, text)
+		}
+
+		o := op{fn: fn, args: make([]byte, len(signature))}
+		for j := range signature {
+			arg := text[i+j]
+			if signature[j] == 'N' {
+				n, ok := position(arg)
+				if !ok {
+					return Rule{}, fmt.Errorf("invalid position %q for %q in %q", arg, fn, text)
+				}
+				arg = byte(n)
+			}
+			o.args[j] = arg
+		}
+		i += len(signature)
+		rule.ops = append(rule.ops, o)
+	}
+	return rule, nil
+}
+
+// position decodes a position argument: 0-9, then A-Z for 10-35
+func position(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10, true
+	}
+	return 0, false
+}
+
+// ParseSet reads a rule file. Blank lines and lines starting with '#' are
+// ignored; rules with unsupported functions are skipped and counted.
+func ParseSet(r io.Reader, name string) (*Set, error) {
+	set := &Set{Name: name}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := Parse(line)
+		if err != nil {
+			set.Skipped++
+			continue
+		}
+		set.Rules = append(set.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(set.Rules) == 0 {
+		return nil, fmt.Errorf("no usable rules in %s", name)
+	}
+	return set, nil
+}
+
+// Load returns the built-in ruleset with the given name, or reads a rule file
+func Load(nameOrPath string) (*Set, error) {
+	if text, ok := builtin[nameOrPath]; ok {
+		return ParseSet(strings.NewReader(text), nameOrPath)
+	}
+
+	f, err := os.Open(nameOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither a built-in ruleset (%s) nor a readable rule file: %w", nameOrPath, strings.Join(BuiltinNames(), ", "), err)
+	}
+	defer f.Close()
+	return ParseSet(f, nameOrPath)
+}
+
+// Apply runs the rule on word, building the result in buf to avoid
+// allocations. It returns false when a rejection function discards the word.
+func (r *Rule) Apply(buf, word []byte) ([]byte, bool) {
+	w := append(buf[:0], word...)
+	for _, o := range r.ops {
+		var ok bool
+		if w, ok = o.apply(w); !ok {
+			return w, false
+		}
+	}
+	return w, true
+}
+
+// Length returns the length of the word the rule produces from a word of n
+// bytes, or -1 when it depends on the word's content
+func (r *Rule) Length(n int) int {
+	for _, o := range r.ops {
+		if n = o.length(n); n < 0 {
+			return -1
+		}
+	}
+	return n
+}
+
+// length mirrors apply for the length of the word alone
+func (o op) length(n int) int {
+	var p, m int
+	if len(o.args) > 0 {
+		p = int(o.args[0])
+	}
+	if len(o.args) > 1 {
+		m = int(o.args[1])
+	}
+
+	switch o.fn {
+	case 'd', 'f', 'q':
+		return 2 * n
+	case 'p':
+		return n * (p + 1)
+	case '$', '^':
+		return n + 1
+	case '[', ']':
+		return max(n-1, 0)
+	case 'D':
+		if p < n {
+			return n - 1
+		}
+	case 'x':
+		if p < n {
+			return min(p+m, n) - p
+		}
+	case 'O':
+		if p < n {
+			return n - (min(p+m, n) - p)
+		}
+	case 'i':
+		if p <= n {
+			return n + 1
+		}
+	case '\'':
+		if p < n {
+			return p
+		}
+	case '@':
+		return -1
+	case 'z', 'Z':
+		if n > 0 {
+			return n + p
+		}
+	case 'y', 'Y':
+		if p <= n {
+			return n + p
+		}
+	}
+	return n
+}
+
+// apply runs one function. Functions given a position past the end of the
+// word leave it unchanged, as hashcat does.
+func (o op) apply(w []byte) ([]byte, bool) {
+	n := len(w)
+	var p, m int
+	if len(o.args) > 0 {
+		p = int(o.args[0])
+	}
+	if len(o.args) > 1 {
+		m = int(o.args[1])
+	}
+
+	switch o.fn {
+	case ':':
+	case 'l':
+		lower(w)
+	case 'u':
+		upper(w)
+	case 'c':
+		lower(w)
+		if n > 0 {
+			upper(w[:1])
+		}
+	case 'C':
+		upper(w)
+		if n > 0 {
+			lower(w[:1])
+		}
+	case 't':
+		for i := range w {
+			w[i] = toggle(w[i])
+		}
+	case 'T':
+		if p < n {
+			w[p] = toggle(w[p])
+		}
+	case 'r':
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			w[i], w[j] = w[j], w[i]
+		}
+	case 'd':
+		w = append(w, w...)
+	case 'p':
+		for i := 0; i < p; i++ {
+			w = append(w, w[:n]...)
+		}
+	case 'f':
+		for i := n - 1; i >= 0; i-- {
+			w = append(w, w[i])
+		}
+	case '{':
+		if n > 1 {
+			first := w[0]
+			copy(w, w[1:])
+			w[n-1] = first
+		}
+	case '}':
+		if n > 1 {
+			last := w[n-1]
+			copy(w[1:], w[:n-1])
+			w[0] = last
+		}
+	case '$':
+		w = append(w, o.args[0])
+	case '^':
+		w = insert(w, 0, o.args[0])
+	case '[':
+		if n > 0 {
+			w = w[:copy(w, w[1:])]
+		}
+	case ']':
+		if n > 0 {
+			w = w[:n-1]
+		}
+	case 'D':
+		if p < n {
+			w = append(w[:p], w[p+1:]...)
+		}
+	case 'x':
+		if p < n {
+			end := min(p+m, n)
+			w = w[:copy(w, w[p:end])]
+		}
+	case 'O':
+		if p < n {
+			end := min(p+m, n)
+			w = append(w[:p], w[end:]...)
+		}
+	case 'i':
+		if p <= n {
+			w = insert(w, p, o.args[1])
+		}
+	case 'o':
+		if p < n {
+			w[p] = o.args[1]
+		}
+	case '\'':
+		if p < n {
+			w = w[:p]
+		}
+	case 's':
+		for i := range w {
+			if w[i] == o.args[0] {
+				w[i] = o.args[1]
+			}
+		}
+	case '@':
+		kept := w[:0]
+		for _, c := range w {
+			if c != o.args[0] {
+				kept = append(kept, c)
+			}
+		}
+		w = kept
+	case 'z':
+		if n > 0 {
+			for i := 0; i < p; i++ {
+				w = insert(w, 0, w[0])
+			}
+		}
+	case 'Z':
+		if n > 0 {
+			for i := 0; i < p; i++ {
+				w = append(w, w[n-1])
+			}
+		}
+	case 'q':
+		w = append(w, w...)
+		for i := n - 1; i >= 0; i-- {
+			w[2*i], w[2*i+1] = w[i], w[i]
+		}
+	case 'k':
+		if n > 1 {
+			w[0], w[1] = w[1], w[0]
+		}
+	case 'K':
+		if n > 1 {
+			w[n-1], w[n-2] = w[n-2], w[n-1]
+		}
+	case '*':
+		if p < n && m < n {
+			w[p], w[m] = w[m], w[p]
+		}
+	case 'L':
+		if p < n {
+			w[p] <<= 1
+		}
+	case 'R':
+		if p < n {
+			w[p] >>= 1
+		}
+	case '+':
+		if p < n {
+			w[p]++
+		}
+	case '-':
+		if p < n {
+			w[p]--
+		}
+	case '.':
+		if p+1 < n {
+			w[p] = w[p+1]
+		}
+	case ',':
+		if p > 0 && p < n {
+			w[p] = w[p-1]
+		}
+	case 'y':
+		if p <= n {
+			w = append(w, w[:p]...)
+			copy(w[p:], w[:n])
+		}
+	case 'Y':
+		if p <= n {
+			w = append(w, w[n-p:n]...)
+		}
+	case 'E':
+		title(w, ' ')
+	case 'e':
+		title(w, o.args[0])
+	case '<':
+		return w, n <= p
+	case '>':
+		return w, n >= p
+	case '_':
+		return w, n == p
+	case '!':
+		return w, bytes.IndexByte(w, o.args[0]) < 0
+	case '/':
+		return w, bytes.IndexByte(w, o.args[0]) >= 0
+	case '(':
+		return w, n > 0 && w[0] == o.args[0]
+	case ')':
+		return w, n > 0 && w[n-1] == o.args[0]
+	case '=':
+		return w, p < n && w[p] == o.args[1]
+	case '%':
+		return w, bytes.Count(w, o.args[1:2]) >= p
+	}
+	return w, true
+}
+
+// insert places c at position p, shifting the rest of the word right
+func insert(w []byte, p int, c byte) []byte {
+	w = append(w, 0)
+	copy(w[p+1:], w[p:])
+	w[p] = c
+	return w
+}
+
+// lower converts ASCII letters to lower case in place
+func lower(w []byte) {
+	for i, c := range w {
+		if c >= 'A' && c <= 'Z' {
+			w[i] = c + 'a' - 'A'
+		}
+	}
+}
+
+// upper converts ASCII letters to upper case in place
+func upper(w []byte) {
+	for i, c := range w {
+		if c >= 'a' && c <= 'z' {
+			w[i] = c - 'a' + 'A'
+		}
+	}
+}
+
+// toggle switches the case of an ASCII letter
+func toggle(c byte) byte {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return c - 'a' + 'A'
+	case c >= 'A' && c <= 'Z':
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// title lower-cases the word and capitalises the first letter and every letter after sep
+func title(w []byte, sep byte) {
+	lower(w)
+	for i := range w {
+		if i == 0 || w[i-1] == sep {
+			upper(w[i : i+1])
+		}
+	}
+}
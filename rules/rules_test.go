@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+// Examples from the hashcat rule-based attack documentation, applied to "p@ssW0rd"
+var applyTests = []struct {
+	rule, word, want string
+	ok               bool
+}{
+	{":", "p@ssW0rd", "p@ssW0rd", true},
+	{"l", "p@ssW0rd", "p@ssw0rd", true},
+	{"u", "p@ssW0rd", "P@SSW0RD", true},
+	{"c", "p@ssW0rd", "P@ssw0rd", true},
+	{"C", "p@ssW0rd", "p@SSW0RD", true},
+	{"t", "p@ssW0rd", "P@SSw0RD", true},
+	{"T3", "p@ssW0rd", "p@sSW0rd", true},
+	{"r", "p@ssW0rd", "dr0Wss@p", true},
+	{"d", "p@ssW0rd", "p@ssW0rdp@ssW0rd", true},
+	{"p2", "p@ssW0rd", "p@ssW0rdp@ssW0rdp@ssW0rd", true},
+	{"f", "p@ssW0rd", "p@ssW0rddr0Wss@p", true},
+	{"{", "p@ssW0rd", "@ssW0rdp", true},
+	{"}", "p@ssW0rd", "dp@ssW0r", true},
+	{"$1", "p@ssW0rd", "p@ssW0rd1", true},
+	{"^1", "p@ssW0rd", "1p@ssW0rd", true},
+	{"[", "p@ssW0rd", "@ssW0rd", true},
+	{"]", "p@ssW0rd", "p@ssW0r", true},
+	{"D3", "p@ssW0rd", "p@sW0rd", true},
+	{"x04", "p@ssW0rd", "p@ss", true},
+	{"O12", "p@ssW0rd", "psW0rd", true},
+	{"i4!", "p@ssW0rd", "p@ss!W0rd", true},
+	{"o3$", "p@ssW0rd", "p@s$W0rd", true},
+	{"'6", "p@ssW0rd", "p@ssW0", true},
+	{"ss$", "p@ssW0rd", "p@$$W0rd", true},
+	{"@s", "p@ssW0rd", "p@W0rd", true},
+	{"z2", "p@ssW0rd", "ppp@ssW0rd", true},
+	{"Z2", "p@ssW0rd", "p@ssW0rddd", true},
+	{"q", "p@ssW0rd", "pp@@ssssWW00rrdd", true},
+	{"k", "p@ssW0rd", "@pssW0rd", true},
+	{"K", "p@ssW0rd", "p@ssW0dr", true},
+	{"*34", "p@ssW0rd", "p@sWs0rd", true},
+	{"L2", "p@ssW0rd", "p@\xe6sW0rd", true},
+	{"R2", "p@ssW0rd", "p@9sW0rd", true},
+	{"+2", "p@ssW0rd", "p@tsW0rd", true},
+	{"-1", "p@ssW0rd", "p?ssW0rd", true},
+	{".1", "p@ssW0rd", "psssW0rd", true},
+	{",1", "p@ssW0rd", "ppssW0rd", true},
+	{"y2", "p@ssW0rd", "p@p@ssW0rd", true},
+	{"Y2", "p@ssW0rd", "p@ssW0rdrd", true},
+	{"E", "p@ssW0rd w0rld", "P@ssw0rd W0rld", true},
+	{"e-", "p@ssW0rd-w0rld", "P@ssw0rd-W0rld", true},
+
+	// Positions past the end leave the word unchanged
+	{"T9", "abc", "abc", true},
+	{"D9", "abc", "abc", true},
+	{"x94", "abc", "abc", true},
+	{"O92", "abc", "abc", true},
+	{"i9!", "abc", "abc", true},
+	{"'9", "abc", "abc", true},
+	{"y9", "abc", "abc", true},
+	{"x14", "abc", "bc", true},
+	{"O14", "abc", "a", true},
+	{"i3!", "abc", "abc!", true},
+	{"z2", "", "", true},
+	{"[", "", "", true},
+
+	// Rejection functions
+	{"<8", "p@ssW0rd", "p@ssW0rd", true},
+	{"<7", "p@ssW0rd", "", false},
+	{">8", "p@ssW0rd", "p@ssW0rd", true},
+	{">9", "p@ssW0rd", "", false},
+	{"_8", "p@ssW0rd", "p@ssW0rd", true},
+	{"_7", "p@ssW0rd", "", false},
+	{"!z", "p@ssW0rd", "p@ssW0rd", true},
+	{"!@", "p@ssW0rd", "", false},
+	{"/@", "p@ssW0rd", "p@ssW0rd", true},
+	{"/z", "p@ssW0rd", "", false},
+	{"(p", "p@ssW0rd", "p@ssW0rd", true},
+	{"(@", "p@ssW0rd", "", false},
+	{")d", "p@ssW0rd", "p@ssW0rd", true},
+	{")p", "p@ssW0rd", "", false},
+	{"=1@", "p@ssW0rd", "p@ssW0rd", true},
+	{"=1p", "p@ssW0rd", "", false},
+	{"%2s", "p@ssW0rd", "p@ssW0rd", true},
+	{"%3s", "p@ssW0rd", "", false},
+
+	// Functions run from left to right
+	{"c $1 $2", "password", "Password12", true},
+	{"r ] ^x", "abc", "xcb", true},
+	{"sa@ $!", "banana", "b@n@n@!", true},
+}
+
+func TestApply(t *testing.T) {
+	var buf []byte
+	for _, tt := range applyTests {
+		rule, err := Parse(tt.rule)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.rule, err)
+			continue
+		}
+
+		word := []byte(tt.word)
+		got, ok := rule.Apply(buf, word)
+		buf = got
+		if ok != tt.ok {
+			t.Errorf("%q on %q: ok = %v, want %v", tt.rule, tt.word, ok, tt.ok)
+			continue
+		}
+		if string(word) != tt.word {
+			t.Errorf("%q modified its input to %q", tt.rule, word)
+		}
+		if ok && string(got) != tt.want {
+			t.Errorf("%q on %q = %q, want %q", tt.rule, tt.word, got, tt.want)
+		}
+		if n := rule.Length(len(tt.word)); ok && n != -1 && n != len(got) {
+			t.Errorf("%q on %q: Length = %d, but Apply produced %d bytes", tt.rule, tt.word, n, len(got))
+		}
+	}
+}
+
+// TestLengthMatchesApply checks Length against Apply for every built-in rule
+// over words of several lengths, so the two cannot drift apart
+func TestLengthMatchesApply(t *testing.T) {
+	words := []string{"", "a", "ab", "abc", "p@ssW0rd", "correcthorsebatterystaple", strings.Repeat("x", 40)}
+	for _, name := range BuiltinNames() {
+		set, err := Load(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf []byte
+		for _, rule := range set.Rules {
+			for _, word := range words {
+				got, ok := rule.Apply(buf, []byte(word))
+				buf = got
+				if n := rule.Length(len(word)); ok && n != -1 && n != len(got) {
+					t.Errorf("%s rule %q on %q: Length = %d, but Apply produced %q", name, rule.Text, word, n, got)
+				}
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{"$", "T", "x1", "Ta", "X123", "o1"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestParseSetSkipsUnsupported(t *testing.T) {
+	set, err := ParseSet(strings.NewReader("# comment\n\n:\nc\nX123\n$1\n"), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Rules) != 3 || set.Skipped != 1 {
+		t.Errorf("got %d rules and %d skipped, want 3 and 1", len(set.Rules), set.Skipped)
+	}
+}
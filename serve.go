@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/sharafdin/crackulator/common"
+)
+
+// runServeRange serves the breached-password range API from a local corpus
+func runServeRange(args []string) {
+	fs := flag.NewFlagSet("serve-range", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: crackulator serve-range [flags] <pwned-passwords.txt|directory>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	source, err := common.OpenRangeSource(fs.Arg(0))
+	if err != nil {
+		exitWithError(err)
+	}
+	defer source.Close()
+
+	fmt.Fprintf(os.Stderr, "Serving %s at http://%s/range/{prefix}\n", fs.Arg(0), *addr)
+	if err := http.ListenAndServe(*addr, common.NewRangeHandler(source)); err != nil {
+		exitWithError(err)
+	}
+}
@@ -2,67 +2,142 @@ package utils
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+
+	"golang.org/x/term"
 )
 
-// GetPasswordInput prompts the user to enter a password
-func GetPasswordInput() string {
-	reader := bufio.NewReader(os.Stdin)
+// stdin is shared by every prompt so buffered answers are not lost between calls
+var stdin = bufio.NewReader(os.Stdin)
+
+// maxConfirmAttempts bounds how often a mistyped confirmation is retried
+const maxConfirmAttempts = 3
+
+// GetPasswordInput prompts the user to enter a password. On a terminal the
+// password is not echoed and, with confirm, has to be typed twice; when
+// stdin is not a terminal a line is read from it instead.
+func GetPasswordInput(confirm bool) (string, error) {
 	fmt.Print("Enter password to analyze: ")
-	password, _ := reader.ReadString('\n')
-	return strings.TrimSpace(password)
+	if !StdinIsTerminal() {
+		return ReadPasswordLine(stdin)
+	}
+
+	fd := int(os.Stdin.Fd())
+	for attempt := 1; ; attempt++ {
+		password, err := readMasked(fd)
+		if err != nil || !confirm {
+			return password, err
+		}
+
+		fmt.Print("Retype the password to confirm: ")
+		again, err := readMasked(fd)
+		if err != nil {
+			return "", err
+		}
+		if again == password {
+			return password, nil
+		}
+		if attempt == maxConfirmAttempts {
+			return "", errors.New("the passwords did not match")
+		}
+		fmt.Print("The passwords do not match. Enter password to analyze: ")
+	}
+}
+
+// readMasked reads a line from the terminal with echo turned off
+func readMasked(fd int) (string, error) {
+	password, err := term.ReadPassword(fd)
+	// The newline typed by the user was not echoed either
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return string(password), nil
+}
+
+// StdinIsTerminal reports whether stdin is an interactive terminal
+func StdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadPasswordLine reads a password from the first line of r. Only the line
+// ending is stripped; surrounding spaces are part of the password.
+func ReadPasswordLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// errNoAnswer is returned by the prompts when stdin ends before an answer
+var errNoAnswer = errors.New("no answer: input ended; pass the choice as a flag or use --no-interactive")
+
+// readAnswer reads one line of the answer to a prompt
+func readAnswer() (string, error) {
+	answer, err := stdin.ReadString('\n')
+	if err == io.EOF && answer == "" {
+		return "", errNoAnswer
+	}
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
 }
 
 // AskYesNo asks a yes/no question and returns true for yes
-func AskYesNo(question string) bool {
-	reader := bufio.NewReader(os.Stdin)
+func AskYesNo(question string) (bool, error) {
 	for {
 		fmt.Print(question + " ")
-		answer, _ := reader.ReadString('\n')
-		answer = strings.TrimSpace(strings.ToLower(answer))
-		
+		answer, err := readAnswer()
+		if err != nil {
+			return false, err
+		}
+		answer = strings.ToLower(answer)
+
 		if answer == "y" || answer == "yes" {
-			return true
+			return true, nil
 		} else if answer == "n" || answer == "no" {
-			return false
+			return false, nil
 		}
-		
+
 		fmt.Println("Please answer with 'y' or 'n'")
 	}
 }
 
 // AskOption asks the user to choose from a list of options
-func AskOption(question string, options []string) string {
-	reader := bufio.NewReader(os.Stdin)
+func AskOption(question string, options []string) (string, error) {
 	fmt.Println(question)
-	
+
 	for i, option := range options {
 		fmt.Printf("%d. %s\n", i+1, option)
 	}
-	
+
 	for {
 		fmt.Print("Enter your choice (1-" + fmt.Sprint(len(options)) + "): ")
-		answer, _ := reader.ReadString('\n')
-		answer = strings.TrimSpace(answer)
-		
+		answer, err := readAnswer()
+		if err != nil {
+			return "", err
+		}
+
 		// Try to convert to int
 		var choice int
-		_, err := fmt.Sscanf(answer, "%d", &choice)
-		
+		_, err = fmt.Sscanf(answer, "%d", &choice)
+
 		if err == nil && choice >= 1 && choice <= len(options) {
-			return options[choice-1]
+			return options[choice-1], nil
 		}
-		
+
 		fmt.Println("Invalid choice. Please try again.")
 	}
 }
 
 // AskInput asks the user for text input
-func AskInput(prompt string) string {
-	reader := bufio.NewReader(os.Stdin)
+func AskInput(prompt string) (string, error) {
 	fmt.Print(prompt + " ")
-	input, _ := reader.ReadString('\n')
-	return strings.TrimSpace(input)
-} 
\ No newline at end of file
+	return readAnswer()
+}